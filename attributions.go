@@ -0,0 +1,100 @@
+package gtfs
+
+import (
+	"github.com/OneBusAway/go-gtfs/csv"
+	"github.com/OneBusAway/go-gtfs/warnings"
+)
+
+// Attribution corresponds to a single row in the attributions.txt file.
+//
+// At most one of Agency, Route and Trip is set. If none are set the attribution applies to the whole feed.
+type Attribution struct {
+	Id               string
+	Agency           *Agency
+	Route            *Route
+	Trip             *ScheduledTrip
+	OrganizationName string
+	IsProducer       bool
+	IsOperator       bool
+	IsAuthority      bool
+	Url              string
+	Email            string
+	Phone            string
+}
+
+func parseAttributions(csv *csv.File, ids *StaticIDs) ([]Attribution, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.OptionalColumn("attribution_id")
+	agencyIDColumn := csv.OptionalColumn("agency_id")
+	routeIDColumn := csv.OptionalColumn("route_id")
+	tripIDColumn := csv.OptionalColumn("trip_id")
+	organizationNameColumn := csv.RequiredColumn("organization_name")
+	isProducerColumn := csv.OptionalColumn("is_producer")
+	isOperatorColumn := csv.OptionalColumn("is_operator")
+	isAuthorityColumn := csv.OptionalColumn("is_authority")
+	urlColumn := csv.OptionalColumn("attribution_url")
+	emailColumn := csv.OptionalColumn("attribution_email")
+	phoneColumn := csv.OptionalColumn("attribution_phone")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var attributions []Attribution
+	for csv.NextRow() {
+		attribution := Attribution{
+			Id:               idColumn.Read(),
+			OrganizationName: organizationNameColumn.Read(),
+			IsProducer:       isProducerColumn.Read() == "1",
+			IsOperator:       isOperatorColumn.Read() == "1",
+			IsAuthority:      isAuthorityColumn.Read() == "1",
+			Url:              urlColumn.Read(),
+			Email:            emailColumn.Read(),
+			Phone:            phoneColumn.Read(),
+		}
+		agencyID := agencyIDColumn.Read()
+		routeID := routeIDColumn.Read()
+		tripID := tripIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		if !attribution.IsProducer && !attribution.IsOperator && !attribution.IsAuthority {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.AttributionWithoutRole{AttributionID: attribution.Id}))
+			continue
+		}
+		numReferences := 0
+		for _, id := range []string{agencyID, routeID, tripID} {
+			if id != "" {
+				numReferences++
+			}
+		}
+		if numReferences > 1 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.ConflictingValues{Columns: []string{"agency_id", "route_id", "trip_id"}}))
+			continue
+		}
+		var ok bool
+		switch {
+		case agencyID != "":
+			attribution.Agency, ok = ids.agencies[agencyID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "agency_id", Value: agencyID}))
+				continue
+			}
+		case routeID != "":
+			attribution.Route, ok = ids.routes[routeID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "route_id", Value: routeID}))
+				continue
+			}
+		case tripID != "":
+			attribution.Trip, ok = ids.trips[tripID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "trip_id", Value: tripID}))
+				continue
+			}
+		}
+		attributions = append(attributions, attribution)
+	}
+	return attributions, w
+}
@@ -0,0 +1,60 @@
+package gtfs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseAttributions(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"attributions.txt",
+		"attribution_id,agency_id,route_id,trip_id,organization_name,is_producer,is_operator,is_authority,attribution_url,attribution_email,attribution_phone",
+		"feed,,,,Producer,1,,,https://example.com,a@example.com,555-0100",
+		"agency,a,,,Operator,0,1,0,,,",
+		"route,,route_id,,Authority,,,1,,,",
+		"trip,,,trip_id,Both,1,1,,,,",
+		"no_role,,,,Nobody,0,0,0,,,",
+		"two_references,a,route_id,,Conflicting,1,,,,,",
+		"unknown_agency,b,,,Unknown,1,,,,,",
+		"unknown_route,,b,,Unknown,1,,,,,",
+		"unknown_trip,,,b,Unknown,1,,,,,",
+	).build()
+
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	if diff := cmp.Diff(static.Attributions, []Attribution{
+		{
+			Id:               "feed",
+			OrganizationName: "Producer",
+			IsProducer:       true,
+			Url:              "https://example.com",
+			Email:            "a@example.com",
+			Phone:            "555-0100",
+		},
+		{
+			Id:               "agency",
+			Agency:           &static.Agencies[0],
+			OrganizationName: "Operator",
+			IsOperator:       true,
+		},
+		{
+			Id:               "route",
+			Route:            &static.Routes[0],
+			OrganizationName: "Authority",
+			IsAuthority:      true,
+		},
+		{
+			Id:               "trip",
+			Trip:             &static.Trips[0],
+			OrganizationName: "Both",
+			IsProducer:       true,
+			IsOperator:       true,
+		},
+	}); diff != "" {
+		t.Errorf("attributions not the same: %s", diff)
+	}
+}
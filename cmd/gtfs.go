@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/OneBusAway/go-gtfs/extensions/nyctalerts"
 	"github.com/OneBusAway/go-gtfs/extensions/nycttrips"
 	"github.com/OneBusAway/go-gtfs/journal"
+	"github.com/OneBusAway/go-gtfs/warnings"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
@@ -34,16 +36,34 @@ func main() {
 			{
 				Name:  "static",
 				Usage: "parse a GTFS static message",
-				Action: func(*cli.Context) error {
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "report",
+						Usage: "print a JSON report of the warnings raised while parsing",
+					},
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "fail if the feed has warnings with severity error or fatal",
+					},
+				},
+				ArgsUsage: "[path]",
+				Action: func(ctx *cli.Context) error {
 					path := "google_transit.zip"
-					b, err := os.ReadFile(path)
-					if err != nil {
-						return fmt.Errorf("failed to read file %s: %w", path, err)
+					if ctx.Args().Len() > 0 {
+						path = ctx.Args().First()
 					}
-					static, err := gtfs.ParseStatic(b, gtfs.ParseStaticOptions{})
+					static, err := gtfs.ParseStaticFile(path, gtfs.ParseStaticOptions{Strict: ctx.Bool("strict")})
 					if err != nil {
 						return fmt.Errorf("failed to parse GTFS static data: %w", err)
 					}
+					if ctx.Bool("report") {
+						b, err := json.MarshalIndent(warnings.NewStaticReport(static.Warnings, 5), "", "  ")
+						if err != nil {
+							return err
+						}
+						fmt.Println(string(b))
+						return nil
+					}
 					fmt.Println("Num trips", len(static.Trips))
 					return nil
 				},
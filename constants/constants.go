@@ -3,5 +3,35 @@ package constants
 type StaticFile string
 
 const (
-	AgencyFile StaticFile = "agency.txt"
+	AgencyFile             StaticFile = "agency.txt"
+	AreasFile              StaticFile = "areas.txt"
+	AttributionsFile       StaticFile = "attributions.txt"
+	BookingRulesFile       StaticFile = "booking_rules.txt"
+	CalendarDatesFile      StaticFile = "calendar_dates.txt"
+	CalendarFile           StaticFile = "calendar.txt"
+	FareAttributesFile     StaticFile = "fare_attributes.txt"
+	FareLegJoinRulesFile   StaticFile = "fare_leg_join_rules.txt"
+	FareLegRulesFile       StaticFile = "fare_leg_rules.txt"
+	FareMediaFile          StaticFile = "fare_media.txt"
+	FareProductsFile       StaticFile = "fare_products.txt"
+	FareRulesFile          StaticFile = "fare_rules.txt"
+	FareTransferRulesFile  StaticFile = "fare_transfer_rules.txt"
+	FeedInfoFile           StaticFile = "feed_info.txt"
+	FrequenciesFile        StaticFile = "frequencies.txt"
+	LevelsFile             StaticFile = "levels.txt"
+	LocationGroupStopsFile StaticFile = "location_group_stops.txt"
+	LocationGroupsFile     StaticFile = "location_groups.txt"
+	LocationsFile          StaticFile = "locations.geojson"
+	PathwaysFile           StaticFile = "pathways.txt"
+	RiderCategoriesFile    StaticFile = "rider_categories.txt"
+	RouteNetworksFile      StaticFile = "route_networks.txt"
+	RoutesFile             StaticFile = "routes.txt"
+	ShapesFile             StaticFile = "shapes.txt"
+	StopAreasFile          StaticFile = "stop_areas.txt"
+	StopTimesFile          StaticFile = "stop_times.txt"
+	StopsFile              StaticFile = "stops.txt"
+	TimeframesFile         StaticFile = "timeframes.txt"
+	TransfersFile          StaticFile = "transfers.txt"
+	TranslationsFile       StaticFile = "translations.txt"
+	TripsFile              StaticFile = "trips.txt"
 )
@@ -89,7 +89,7 @@ func (p *File) MissingRequiredColumns() []string {
 
 func (c RequiredColumn) Read() string {
 	r := c.f.currentRow
-	if c.i >= len(r.cells) || r.cells[c.i] == "" {
+	if c.i < 0 || c.i >= len(r.cells) || r.cells[c.i] == "" {
 		r.missingKeys = append(r.missingKeys, c.s)
 		return ""
 	}
@@ -118,6 +118,11 @@ func (c OptionalColumn) Read() string {
 	return s
 }
 
+// Present returns whether the column is in the header of the file.
+func (c OptionalColumn) Present() bool {
+	return c.i >= 0
+}
+
 func (c OptionalColumn) ReadOr(s string) string {
 	if c.i < 0 {
 		return s
@@ -152,7 +157,8 @@ func (f *File) RowContent() []string {
 	if f.currentRow == nil {
 		return []string{}
 	}
-	return f.currentRow.cells
+	// The cells are reused for the next row, so a copy is returned.
+	return append([]string(nil), f.currentRow.cells...)
 }
 
 func (f *File) RowNumber() int {
@@ -0,0 +1,43 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+)
+
+func FuzzNew(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"stop_id,stop_name\n1,a\n2,b",
+		"\ufeffstop_id,stop_name\n1,a",
+		"stop_id,stop_name\n1\n2,b,c",
+		"stop_id\n\"1\n",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		file, err := New(constants.StopsFile, io.NopCloser(strings.NewReader(content)))
+		if err != nil {
+			return
+		}
+		var required []RequiredColumn
+		var optional []OptionalColumn
+		for _, column := range append(file.HeaderContent(), "stop_id", "missing") {
+			required = append(required, file.RequiredColumn(column))
+			optional = append(optional, file.OptionalColumn(column))
+		}
+		for file.NextRow() {
+			for i := range required {
+				required[i].Read()
+				optional[i].Read()
+				optional[i].ReadOr("default")
+			}
+			file.RowContent()
+			file.MissingRowKeys()
+		}
+		file.Close()
+	})
+}
@@ -37,6 +37,43 @@ func parseBikesAllowed(s string) BikesAllowed {
 	}
 }
 
+// BookingType describes how far in advance a flex trip must be booked.
+//
+// This is a Go representation of the enum described in the `booking_type` field of `booking_rules.txt`.
+type BookingType int32
+
+const (
+	BookingType_RealTime  BookingType = 0
+	BookingType_SameDay   BookingType = 1
+	BookingType_PriorDays BookingType = 2
+)
+
+func parseBookingType(s string) (BookingType, bool) {
+	switch s {
+	case "0":
+		return BookingType_RealTime, true
+	case "1":
+		return BookingType_SameDay, true
+	case "2":
+		return BookingType_PriorDays, true
+	default:
+		return BookingType_RealTime, false
+	}
+}
+
+func (b BookingType) String() string {
+	switch b {
+	case BookingType_RealTime:
+		return "REAL_TIME"
+	case BookingType_SameDay:
+		return "SAME_DAY"
+	case BookingType_PriorDays:
+		return "PRIOR_DAYS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // DirectionID is a mechanism for distinguishing between trips going in the opposite direction.
 type DirectionID uint8
 
@@ -110,6 +147,227 @@ func (t ExactTimes) String() string {
 	}
 }
 
+// DurationLimitType describes which legs of a transfer a duration limit is measured between.
+//
+// This is a Go representation of the enum described in the `duration_limit_type` field of `fare_transfer_rules.txt`.
+type DurationLimitType int32
+
+const (
+	// Between the departure fare validation of the current leg and the arrival fare validation of the next leg.
+	DurationLimitType_DepartureToArrival DurationLimitType = 0
+	// Between the departure fare validation of the current leg and the departure fare validation of the next leg.
+	DurationLimitType_DepartureToDeparture DurationLimitType = 1
+	// Between the arrival fare validation of the current leg and the departure fare validation of the next leg.
+	DurationLimitType_ArrivalToDeparture DurationLimitType = 2
+	// Between the arrival fare validation of the current leg and the arrival fare validation of the next leg.
+	DurationLimitType_ArrivalToArrival DurationLimitType = 3
+)
+
+func parseDurationLimitType(s string) DurationLimitType {
+	switch s {
+	case "1":
+		return DurationLimitType_DepartureToDeparture
+	case "2":
+		return DurationLimitType_ArrivalToDeparture
+	case "3":
+		return DurationLimitType_ArrivalToArrival
+	default:
+		return DurationLimitType_DepartureToArrival
+	}
+}
+
+func (t DurationLimitType) String() string {
+	switch t {
+	case DurationLimitType_DepartureToArrival:
+		return "DEPARTURE_TO_ARRIVAL"
+	case DurationLimitType_DepartureToDeparture:
+		return "DEPARTURE_TO_DEPARTURE"
+	case DurationLimitType_ArrivalToDeparture:
+		return "ARRIVAL_TO_DEPARTURE"
+	case DurationLimitType_ArrivalToArrival:
+		return "ARRIVAL_TO_ARRIVAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FareMediaType describes the type of a fare media.
+//
+// This is a Go representation of the enum described in the `fare_media_type` field of `fare_media.txt`.
+type FareMediaType int32
+
+const (
+	FareMediaType_None              FareMediaType = 0
+	FareMediaType_PaperTicket       FareMediaType = 1
+	FareMediaType_TransitCard       FareMediaType = 2
+	FareMediaType_ContactlessEMV    FareMediaType = 3
+	FareMediaType_MobileApplication FareMediaType = 4
+)
+
+func parseFareMediaType(s string) (FareMediaType, bool) {
+	switch s {
+	case "0":
+		return FareMediaType_None, true
+	case "1":
+		return FareMediaType_PaperTicket, true
+	case "2":
+		return FareMediaType_TransitCard, true
+	case "3":
+		return FareMediaType_ContactlessEMV, true
+	case "4":
+		return FareMediaType_MobileApplication, true
+	default:
+		return FareMediaType_None, false
+	}
+}
+
+func (t FareMediaType) String() string {
+	switch t {
+	case FareMediaType_None:
+		return "NONE"
+	case FareMediaType_PaperTicket:
+		return "PAPER_TICKET"
+	case FareMediaType_TransitCard:
+		return "TRANSIT_CARD"
+	case FareMediaType_ContactlessEMV:
+		return "CONTACTLESS_EMV"
+	case FareMediaType_MobileApplication:
+		return "MOBILE_APPLICATION"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FarePaymentMethod describes when a fare must be paid.
+//
+// This is a Go representation of the enum described in the `payment_method` field of `fare_attributes.txt`.
+type FarePaymentMethod int32
+
+const (
+	FarePaymentMethod_OnBoard        FarePaymentMethod = 0
+	FarePaymentMethod_BeforeBoarding FarePaymentMethod = 1
+)
+
+func parseFarePaymentMethod(s string) (FarePaymentMethod, bool) {
+	switch s {
+	case "0":
+		return FarePaymentMethod_OnBoard, true
+	case "1":
+		return FarePaymentMethod_BeforeBoarding, true
+	default:
+		return FarePaymentMethod_OnBoard, false
+	}
+}
+
+func (m FarePaymentMethod) String() string {
+	switch m {
+	case FarePaymentMethod_OnBoard:
+		return "ON_BOARD"
+	case FarePaymentMethod_BeforeBoarding:
+		return "BEFORE_BOARDING"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FareTransferType describes how the cost of a transfer between two legs is calculated.
+//
+// This is a Go representation of the enum described in the `fare_transfer_type` field of `fare_transfer_rules.txt`.
+// In the descriptions, A is the cost of the first leg, B the cost of the second leg and AB the cost of the transfer.
+type FareTransferType int32
+
+const (
+	// The cost is A + AB.
+	FareTransferType_FromLegPlusTransfer FareTransferType = 0
+	// The cost is A + AB + B.
+	FareTransferType_FromLegPlusTransferPlusToLeg FareTransferType = 1
+	// The cost is AB.
+	FareTransferType_Transfer FareTransferType = 2
+)
+
+func parseFareTransferType(s string) (FareTransferType, bool) {
+	switch s {
+	case "0":
+		return FareTransferType_FromLegPlusTransfer, true
+	case "1":
+		return FareTransferType_FromLegPlusTransferPlusToLeg, true
+	case "2":
+		return FareTransferType_Transfer, true
+	default:
+		return FareTransferType_FromLegPlusTransfer, false
+	}
+}
+
+func (t FareTransferType) String() string {
+	switch t {
+	case FareTransferType_FromLegPlusTransfer:
+		return "FROM_LEG_PLUS_TRANSFER"
+	case FareTransferType_FromLegPlusTransferPlusToLeg:
+		return "FROM_LEG_PLUS_TRANSFER_PLUS_TO_LEG"
+	case FareTransferType_Transfer:
+		return "TRANSFER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// PathwayMode describes the type of a pathway.
+//
+// This is a Go representation of the enum described in the `pathway_mode` field of `pathways.txt`.
+type PathwayMode int32
+
+const (
+	PathwayMode_Walkway        PathwayMode = 1
+	PathwayMode_Stairs         PathwayMode = 2
+	PathwayMode_MovingSidewalk PathwayMode = 3
+	PathwayMode_Escalator      PathwayMode = 4
+	PathwayMode_Elevator       PathwayMode = 5
+	PathwayMode_FareGate       PathwayMode = 6
+	PathwayMode_ExitGate       PathwayMode = 7
+)
+
+func parsePathwayMode(s string) (PathwayMode, bool) {
+	switch s {
+	case "1":
+		return PathwayMode_Walkway, true
+	case "2":
+		return PathwayMode_Stairs, true
+	case "3":
+		return PathwayMode_MovingSidewalk, true
+	case "4":
+		return PathwayMode_Escalator, true
+	case "5":
+		return PathwayMode_Elevator, true
+	case "6":
+		return PathwayMode_FareGate, true
+	case "7":
+		return PathwayMode_ExitGate, true
+	default:
+		return PathwayMode_Walkway, false
+	}
+}
+
+func (m PathwayMode) String() string {
+	switch m {
+	case PathwayMode_Walkway:
+		return "WALKWAY"
+	case PathwayMode_Stairs:
+		return "STAIRS"
+	case PathwayMode_MovingSidewalk:
+		return "MOVING_SIDEWALK"
+	case PathwayMode_Escalator:
+		return "ESCALATOR"
+	case PathwayMode_Elevator:
+		return "ELEVATOR"
+	case PathwayMode_FareGate:
+		return "FARE_GATE"
+	case PathwayMode_ExitGate:
+		return "EXIT_GATE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // PickupDropOffPolicy describes the pickup or drop-off policy for a route or scheduled trip.
 //
 // This is a Go representation of the enum described in the `continuous_pickup` field of `routes.txt`,
@@ -918,6 +1176,10 @@ const (
 	TransferType_Timed        TransferType = 1
 	TransferType_RequiresTime TransferType = 2
 	TransferType_NotPossible  TransferType = 3
+	// Riders can stay on board the vehicle when the from trip ends and the to trip starts.
+	TransferType_InSeat TransferType = 4
+	// Riders must alight when the from trip ends, even though the same vehicle operates the to trip.
+	TransferType_InSeatNotAllowed TransferType = 5
 )
 
 func parseTransferType(s string) TransferType {
@@ -928,6 +1190,10 @@ func parseTransferType(s string) TransferType {
 		return TransferType_RequiresTime
 	case "3":
 		return TransferType_NotPossible
+	case "4":
+		return TransferType_InSeat
+	case "5":
+		return TransferType_InSeatNotAllowed
 	default:
 		return TransferType_Recommended
 	}
@@ -943,6 +1209,10 @@ func (t TransferType) String() string {
 		return "REQUIRES_TIME"
 	case TransferType_NotPossible:
 		return "NOT_POSSIBLE"
+	case TransferType_InSeat:
+		return "IN_SEAT"
+	case TransferType_InSeatNotAllowed:
+		return "IN_SEAT_NOT_ALLOWED"
 	default:
 		return "UNKNOWN"
 	}
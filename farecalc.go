@@ -0,0 +1,673 @@
+package gtfs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FareLeg is a single leg of an itinerary for which a fare is to be calculated.
+type FareLeg struct {
+	// Trip the leg is made on.
+	Trip *ScheduledTrip
+	// Stop at which the rider boards the trip.
+	From *Stop
+	// Stop at which the rider alights the trip.
+	To *Stop
+	// Time at which the rider boards the trip.
+	DepartureTime time.Time
+	// Time at which the rider alights the trip.
+	ArrivalTime time.Time
+}
+
+// FareCalculationOptions contains options for calculating fares.
+type FareCalculationOptions struct {
+	// Rider category to calculate the fare for when using GTFS Fares v2 data.
+	// If nil, prices for the default rider category are used.
+	RiderCategory *RiderCategory
+	// Fare media to calculate the fare for when using GTFS Fares v2 data.
+	// If nil, the cheapest price across all fare media is used.
+	FareMedia *FareMedia
+}
+
+// Fare is the result of calculating the fare for an itinerary.
+type Fare struct {
+	// Items that need to be purchased to make the itinerary, in the order they are purchased.
+	Items []FareItem
+	// Total price of the itinerary.
+	Total float64
+	// Currency of the total price.
+	Currency string
+}
+
+// FareItem is a single purchase needed to make an itinerary.
+//
+// Exactly one of FareAttribute and FareProduct is set, depending on whether the fare was
+// calculated using GTFS Fares v1 or Fares v2 data.
+type FareItem struct {
+	// Indices of the legs in the itinerary that this item is used for.
+	Legs          []int
+	FareAttribute *FareAttribute
+	FareProduct   *FareProduct
+	Amount        float64
+	Currency      string
+}
+
+// CalculateFare calculates the fare for an itinerary made up of the provided legs.
+//
+// If the feed contains GTFS Fares v2 leg rules these are used. Otherwise the GTFS Fares v1
+// fare attributes and rules are used.
+// An error is returned if no fare applies to one of the legs.
+func (s *Static) CalculateFare(legs []FareLeg, opts FareCalculationOptions) (*Fare, error) {
+	for i, leg := range legs {
+		if leg.Trip == nil || leg.Trip.Route == nil || leg.From == nil || leg.To == nil {
+			return nil, fmt.Errorf("leg %d is missing its trip, route or stops", i)
+		}
+	}
+	var items []FareItem
+	var err error
+	switch {
+	case len(s.FareLegRules) > 0:
+		items, err = s.calculateFareV2(legs, opts)
+	case len(s.FareAttributes) > 0:
+		items, err = s.calculateFareV1(legs)
+	default:
+		return nil, fmt.Errorf("GTFS static feed contains no fare data")
+	}
+	if err != nil {
+		return nil, err
+	}
+	fare := &Fare{Items: items}
+	for _, item := range items {
+		if fare.Currency == "" {
+			fare.Currency = item.Currency
+		} else if item.Currency != fare.Currency {
+			return nil, fmt.Errorf("fare has prices in multiple currencies %s and %s", fare.Currency, item.Currency)
+		}
+		fare.Total += item.Amount
+	}
+	return fare, nil
+}
+
+// calculateFareV1 calculates a fare using fare_attributes.txt and fare_rules.txt.
+//
+// Each leg is assigned the cheapest fare that applies to it. A leg is free if the fare purchased
+// for an earlier leg also applies to it and that fare still has transfers remaining.
+func (s *Static) calculateFareV1(legs []FareLeg) ([]FareItem, error) {
+	fareToRules := map[*FareAttribute][]*FareRule{}
+	for i := range s.FareRules {
+		rule := &s.FareRules[i]
+		fareToRules[rule.Fare] = append(fareToRules[rule.Fare], rule)
+	}
+	var items []FareItem
+	// Index of the item purchased most recently, or -1 if no item has been purchased.
+	current := -1
+	var transfersUsed int32
+	for i, leg := range legs {
+		applicable := s.applicableFaresV1(leg, fareToRules)
+		if len(applicable) == 0 {
+			return nil, fmt.Errorf("no fare applies to leg %d on trip %s", i, leg.Trip.ID)
+		}
+		if current >= 0 && canTransferV1(&items[current], transfersUsed, legs[items[current].Legs[0]], leg, applicable) {
+			items[current].Legs = append(items[current].Legs, i)
+			transfersUsed++
+			continue
+		}
+		cheapest := applicable[0]
+		for _, fare := range applicable[1:] {
+			if fare.Price < cheapest.Price {
+				cheapest = fare
+			}
+		}
+		items = append(items, FareItem{
+			Legs:          []int{i},
+			FareAttribute: cheapest,
+			Amount:        cheapest.Price,
+			Currency:      cheapest.CurrencyType,
+		})
+		current = len(items) - 1
+		transfersUsed = 0
+	}
+	return items, nil
+}
+
+func canTransferV1(current *FareItem, transfersUsed int32, firstLeg, leg FareLeg, applicable []*FareAttribute) bool {
+	fare := current.FareAttribute
+	if fare.Transfers != nil && transfersUsed >= *fare.Transfers {
+		return false
+	}
+	if fare.TransferDuration != nil &&
+		leg.DepartureTime.Sub(firstLeg.DepartureTime) > time.Duration(*fare.TransferDuration)*time.Second {
+		return false
+	}
+	for _, other := range applicable {
+		if other == fare {
+			return true
+		}
+	}
+	return false
+}
+
+// applicableFaresV1 returns the fares that apply to the leg.
+//
+// A fare applies if one of its rules matches the leg's route, origin zone and destination zone.
+// If a fare has rules with the contains_id field set, the zones passed through by the leg must be
+// exactly the zones in those rules. A fare without any rules applies to every leg of its agency.
+func (s *Static) applicableFaresV1(leg FareLeg, fareToRules map[*FareAttribute][]*FareRule) []*FareAttribute {
+	originZone := stopZoneID(leg.From)
+	destinationZone := stopZoneID(leg.To)
+	containedZones := legContainedZoneIDs(leg)
+	var applicable []*FareAttribute
+	for i := range s.FareAttributes {
+		fare := &s.FareAttributes[i]
+		if fare.Agency != nil && leg.Trip.Route.Agency != nil && fare.Agency.Id != leg.Trip.Route.Agency.Id {
+			continue
+		}
+		rules, hasRules := fareToRules[fare]
+		if !hasRules {
+			applicable = append(applicable, fare)
+			continue
+		}
+		// Rules that differ only in their contains_id field are grouped, as the leg must pass
+		// through all of the group's zones.
+		type ruleKey struct {
+			routeID, originZoneID, destinationZoneID string
+		}
+		groupToZones := map[ruleKey]map[string]bool{}
+		var groups []ruleKey
+		for _, rule := range rules {
+			var key ruleKey
+			if rule.Route != nil {
+				if rule.Route.Id != leg.Trip.Route.Id {
+					continue
+				}
+				key.routeID = rule.Route.Id
+			}
+			if rule.Origin != nil {
+				if rule.Origin.Id != originZone {
+					continue
+				}
+				key.originZoneID = rule.Origin.Id
+			}
+			if rule.Destination != nil {
+				if rule.Destination.Id != destinationZone {
+					continue
+				}
+				key.destinationZoneID = rule.Destination.Id
+			}
+			if _, ok := groupToZones[key]; !ok {
+				groupToZones[key] = map[string]bool{}
+				groups = append(groups, key)
+			}
+			if rule.Contains != nil {
+				groupToZones[key][rule.Contains.Id] = true
+			}
+		}
+		for _, group := range groups {
+			zones := groupToZones[group]
+			if len(zones) == 0 || equalStringSets(zones, containedZones) {
+				applicable = append(applicable, fare)
+				break
+			}
+		}
+	}
+	return applicable
+}
+
+// stopZoneID returns the fare zone of the stop, inheriting the zone of the parent station if unset.
+func stopZoneID(stop *Stop) string {
+	for ; stop != nil; stop = stop.Parent {
+		if stop.ZoneId != "" {
+			return stop.ZoneId
+		}
+	}
+	return ""
+}
+
+// legContainedZoneIDs returns the zones of all stops the leg passes through, including the
+// boarding and alighting stops.
+func legContainedZoneIDs(leg FareLeg) map[string]bool {
+	zones := map[string]bool{}
+	addZone := func(stop *Stop) {
+		if zoneID := stopZoneID(stop); zoneID != "" {
+			zones[zoneID] = true
+		}
+	}
+	addZone(leg.From)
+	addZone(leg.To)
+	from, to := legStopTimeIndices(leg)
+	for i := from; i >= 0 && i <= to; i++ {
+		addZone(leg.Trip.StopTimes[i].Stop)
+	}
+	return zones
+}
+
+// legStopTimeIndices returns the indices of the boarding and alighting stop times of the leg in
+// the trip's stop times, or (-1, -1) if they cannot be found.
+func legStopTimeIndices(leg FareLeg) (int, int) {
+	from := -1
+	for i := range leg.Trip.StopTimes {
+		stop := leg.Trip.StopTimes[i].Stop
+		if stop == nil {
+			continue
+		}
+		if from < 0 && stop.Id == leg.From.Id {
+			from = i
+		} else if from >= 0 && stop.Id == leg.To.Id {
+			return from, i
+		}
+	}
+	return -1, -1
+}
+
+func equalStringSets(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// effectiveFareLeg is one or more legs of an itinerary that are treated as a single leg for the
+// purposes of GTFS Fares v2 fare calculation.
+type effectiveFareLeg struct {
+	legs          []int
+	networkID     string
+	from          *Stop
+	to            *Stop
+	departureTime time.Time
+	arrivalTime   time.Time
+}
+
+// calculateFareV2 calculates a fare using the GTFS Fares v2 files.
+func (s *Static) calculateFareV2(legs []FareLeg, opts FareCalculationOptions) ([]FareItem, error) {
+	effectiveLegs := s.joinFareLegs(legs)
+	stopToAreas := map[string][]*Area{}
+	for i := range s.Areas {
+		area := &s.Areas[i]
+		for _, stop := range area.Stops {
+			stopToAreas[stop.Id] = append(stopToAreas[stop.Id], area)
+		}
+	}
+
+	var items []FareItem
+	// State of the current transfer sequence.
+	var previousRule *FareLegRule
+	var sequenceStart effectiveFareLeg
+	var transfersInSequence int32
+	// Index of the first item purchased in the sequence.
+	var sequenceFirstItem int
+	// Index of the item that covers the legs reached with a transfer that has no leg cost.
+	var sequenceItem int
+	var sequenceLegs []int
+	for i, leg := range effectiveLegs {
+		rule, price, err := s.matchFareLegRule(leg, stopToAreas, opts)
+		if err != nil {
+			return nil, err
+		}
+		legItem := FareItem{
+			Legs:        append([]int(nil), leg.legs...),
+			FareProduct: rule.FareProduct,
+			Amount:      price.Amount,
+			Currency:    price.Currency,
+		}
+		var transferRule *FareTransferRule
+		if i > 0 {
+			transferRule = s.matchFareTransferRule(previousRule, rule, sequenceStart, leg, transfersInSequence)
+		}
+		previousRule = rule
+		if transferRule == nil {
+			items = append(items, legItem)
+			sequenceStart = leg
+			transfersInSequence = 0
+			sequenceFirstItem = len(items) - 1
+			sequenceItem = len(items) - 1
+			sequenceLegs = append([]int(nil), leg.legs...)
+			continue
+		}
+		transfersInSequence++
+		sequenceLegs = append(sequenceLegs, leg.legs...)
+		var transferItem *FareItem
+		if transferRule.FareProduct != nil {
+			transferPrice, ok := selectFareProductPrice(transferRule.FareProduct, opts)
+			if !ok {
+				return nil, fmt.Errorf("no price for transfer fare product %s", transferRule.FareProduct.Id)
+			}
+			transferItem = &FareItem{
+				Legs:        append([]int(nil), leg.legs...),
+				FareProduct: transferRule.FareProduct,
+				Amount:      transferPrice.Amount,
+				Currency:    transferPrice.Currency,
+			}
+		}
+		switch transferRule.FareTransferType {
+		case FareTransferType_Transfer:
+			// The cost of the transfer replaces the cost of all legs in the sequence so far.
+			// If the transfer has no fare product, the legs already purchased keep their cost and the to-leg is free.
+			if transferItem == nil {
+				items[sequenceItem].Legs = append(items[sequenceItem].Legs, leg.legs...)
+				continue
+			}
+			transferItem.Legs = append([]int(nil), sequenceLegs...)
+			items = append(items[:sequenceFirstItem], *transferItem)
+			sequenceItem = sequenceFirstItem
+		case FareTransferType_FromLegPlusTransferPlusToLeg:
+			// The to-leg is purchased separately, and later transfers in the sequence are from it.
+			if transferItem != nil {
+				items = append(items, *transferItem)
+			}
+			items = append(items, legItem)
+			sequenceItem = len(items) - 1
+		default:
+			items[sequenceItem].Legs = append(items[sequenceItem].Legs, leg.legs...)
+			if transferItem != nil {
+				items = append(items, *transferItem)
+			}
+		}
+	}
+	return items, nil
+}
+
+// joinFareLegs joins consecutive legs that match a rule in fare_leg_join_rules.txt.
+func (s *Static) joinFareLegs(legs []FareLeg) []effectiveFareLeg {
+	var effectiveLegs []effectiveFareLeg
+	for i, leg := range legs {
+		networkID := leg.Trip.Route.NetworkId
+		if i > 0 {
+			last := &effectiveLegs[len(effectiveLegs)-1]
+			previous := legs[i-1]
+			if s.fareLegsJoin(previous, leg) {
+				last.legs = append(last.legs, i)
+				last.to = leg.To
+				last.arrivalTime = leg.ArrivalTime
+				// The joined leg is only in a network if all of the joined legs are in the same network.
+				if last.networkID != networkID {
+					last.networkID = ""
+				}
+				continue
+			}
+		}
+		effectiveLegs = append(effectiveLegs, effectiveFareLeg{
+			legs:          []int{i},
+			networkID:     networkID,
+			from:          leg.From,
+			to:            leg.To,
+			departureTime: leg.DepartureTime,
+			arrivalTime:   leg.ArrivalTime,
+		})
+	}
+	return effectiveLegs
+}
+
+func (s *Static) fareLegsJoin(previous, next FareLeg) bool {
+	for _, rule := range s.FareLegJoinRules {
+		if rule.FromNetworkId != previous.Trip.Route.NetworkId || rule.ToNetworkId != next.Trip.Route.NetworkId {
+			continue
+		}
+		if rule.FromStop != nil && !stopIsOrIsWithin(previous.To, rule.FromStop) {
+			continue
+		}
+		if rule.ToStop != nil && !stopIsOrIsWithin(next.From, rule.ToStop) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// stopIsOrIsWithin returns whether the stop is the other stop or one of its descendants.
+func stopIsOrIsWithin(stop, other *Stop) bool {
+	for ; stop != nil; stop = stop.Parent {
+		if stop.Id == other.Id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFareLegRule finds the fare leg rule for the leg and the price of its fare product.
+//
+// As in the GTFS specification, a rule with an empty network, area or timeframe field only matches a leg
+// if no rule has a value in that field that matches the leg. If fare_leg_rules.txt has a rule_priority column,
+// empty fields instead match every leg and the rules with the highest priority take precedence.
+// Among the remaining rules the cheapest price is chosen.
+func (s *Static) matchFareLegRule(leg effectiveFareLeg, stopToAreas map[string][]*Area, opts FareCalculationOptions) (*FareLegRule, FareProductPrice, error) {
+	fields := make([]fareLegRuleFields, len(s.FareLegRules))
+	var specificMatch [numFareLegRuleFields]bool
+	for i := range s.FareLegRules {
+		rule := &s.FareLegRules[i]
+		fields[i] = matchFareLegRuleFields(rule, leg, stopToAreas)
+		for j := range fields[i].set {
+			if fields[i].set[j] && fields[i].matches[j] {
+				specificMatch[j] = true
+			}
+		}
+	}
+	var bestRule *FareLegRule
+	var bestPrice FareProductPrice
+	var bestPriority int32
+	for i := range s.FareLegRules {
+		rule := &s.FareLegRules[i]
+		if !fields[i].ruleMatches(specificMatch, s.FareLegRulePriorities) {
+			continue
+		}
+		price, ok := selectFareProductPrice(rule.FareProduct, opts)
+		if !ok {
+			continue
+		}
+		var priority int32
+		if rule.RulePriority != nil {
+			priority = *rule.RulePriority
+		}
+		better := bestRule == nil ||
+			priority > bestPriority ||
+			(priority == bestPriority && price.Amount < bestPrice.Amount)
+		if better {
+			bestRule, bestPrice, bestPriority = rule, price, priority
+		}
+	}
+	if bestRule == nil {
+		return nil, FareProductPrice{}, fmt.Errorf("no fare leg rule applies to legs %v", leg.legs)
+	}
+	return bestRule, bestPrice, nil
+}
+
+// numFareLegRuleFields is the number of fields of a fare leg rule that can be empty to match any leg:
+// the network, the from and to areas, and the from and to timeframes.
+const numFareLegRuleFields = 5
+
+// fareLegRuleFields records, for each field of a fare leg rule that can be empty, whether the field is set
+// and whether it matches a leg.
+type fareLegRuleFields struct {
+	set     [numFareLegRuleFields]bool
+	matches [numFareLegRuleFields]bool
+}
+
+func matchFareLegRuleFields(rule *FareLegRule, leg effectiveFareLeg, stopToAreas map[string][]*Area) fareLegRuleFields {
+	var f fareLegRuleFields
+	f.set = [numFareLegRuleFields]bool{
+		rule.NetworkId != "",
+		rule.FromArea != nil,
+		rule.ToArea != nil,
+		rule.FromTimeframeGroupId != "",
+		rule.ToTimeframeGroupId != "",
+	}
+	f.matches = [numFareLegRuleFields]bool{
+		rule.NetworkId == leg.networkID,
+		rule.FromArea != nil && stopInArea(leg.from, rule.FromArea, stopToAreas),
+		rule.ToArea != nil && stopInArea(leg.to, rule.ToArea, stopToAreas),
+		rule.FromTimeframeGroupId != "" && timeframesContain(rule.FromTimeframes, leg.departureTime),
+		rule.ToTimeframeGroupId != "" && timeframesContain(rule.ToTimeframes, leg.arrivalTime),
+	}
+	return f
+}
+
+// ruleMatches returns whether the rule matches the leg, given which fields have a rule whose value matches the leg.
+func (f *fareLegRuleFields) ruleMatches(specificMatch [numFareLegRuleFields]bool, emptyMatchesAll bool) bool {
+	for i := range f.set {
+		if f.set[i] {
+			if !f.matches[i] {
+				return false
+			}
+		} else if specificMatch[i] && !emptyMatchesAll {
+			return false
+		}
+	}
+	return true
+}
+
+// stopInArea returns whether the stop, or one of its ancestors, is in the area.
+func stopInArea(stop *Stop, area *Area, stopToAreas map[string][]*Area) bool {
+	for ; stop != nil; stop = stop.Parent {
+		for _, other := range stopToAreas[stop.Id] {
+			if other.Id == area.Id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// timeframesContain returns whether the instant is within one of the timeframes.
+//
+// Timeframes are evaluated in the timezone of the dates of their service, which is the timezone the feed
+// was parsed with.
+func timeframesContain(timeframes []*Timeframe, t time.Time) bool {
+	for _, timeframe := range timeframes {
+		if timeframe.Service == nil {
+			continue
+		}
+		t := t.In(timeframe.Service.StartDate.Location())
+		if !timeframe.Service.runsOn(t) {
+			continue
+		}
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		if timeOfDay := t.Sub(midnight); timeframe.StartTime <= timeOfDay && timeOfDay < timeframe.EndTime {
+			return true
+		}
+	}
+	return false
+}
+
+// runsOn returns whether the service runs on the calendar date of the provided time.
+func (service *Service) runsOn(t time.Time) bool {
+	sameDate := func(d time.Time) bool {
+		return d.Year() == t.Year() && d.Month() == t.Month() && d.Day() == t.Day()
+	}
+	for _, d := range service.RemovedDates {
+		if sameDate(d) {
+			return false
+		}
+	}
+	for _, d := range service.AddedDates {
+		if sameDate(d) {
+			return true
+		}
+	}
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(service.StartDate.Year(), service.StartDate.Month(), service.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(service.EndDate.Year(), service.EndDate.Month(), service.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(start) || date.After(end) {
+		return false
+	}
+	return [7]bool{
+		service.Sunday,
+		service.Monday,
+		service.Tuesday,
+		service.Wednesday,
+		service.Thursday,
+		service.Friday,
+		service.Saturday,
+	}[t.Weekday()]
+}
+
+// selectFareProductPrice returns the cheapest price of the fare product for the rider category
+// and fare media in the options.
+func selectFareProductPrice(product *FareProduct, opts FareCalculationOptions) (FareProductPrice, bool) {
+	var candidates []FareProductPrice
+	for _, price := range product.Prices {
+		if opts.RiderCategory != nil {
+			if price.RiderCategory == nil || price.RiderCategory.Id != opts.RiderCategory.Id {
+				continue
+			}
+		} else if price.RiderCategory != nil && !price.RiderCategory.IsDefault {
+			continue
+		}
+		if opts.FareMedia != nil && price.Media != nil && price.Media.Id != opts.FareMedia.Id {
+			continue
+		}
+		candidates = append(candidates, price)
+	}
+	if len(candidates) == 0 {
+		return FareProductPrice{}, false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Amount < candidates[j].Amount
+	})
+	return candidates[0], true
+}
+
+// matchFareTransferRule finds the fare transfer rule that applies when transferring from a leg
+// matching the previous rule to a leg matching the next rule, or nil if no rule applies.
+//
+// As in the GTFS specification, a rule with an empty leg group field only matches if no rule has that
+// leg group in the field. If several rules match, the one with the smallest transfer count that allows
+// the transfer is chosen, and after that the first rule in the feed.
+func (s *Static) matchFareTransferRule(previous, next *FareLegRule, sequenceStart, leg effectiveFareLeg, transfersInSequence int32) *FareTransferRule {
+	hasFromGroup, hasToGroup := false, false
+	for i := range s.FareTransferRules {
+		rule := &s.FareTransferRules[i]
+		if rule.FromLegGroupId != "" && rule.FromLegGroupId == previous.LegGroupId {
+			hasFromGroup = true
+		}
+		if rule.ToLegGroupId != "" && rule.ToLegGroupId == next.LegGroupId {
+			hasToGroup = true
+		}
+	}
+	var best *FareTransferRule
+	for i := range s.FareTransferRules {
+		rule := &s.FareTransferRules[i]
+		if rule.FromLegGroupId != previous.LegGroupId && (rule.FromLegGroupId != "" || hasFromGroup) {
+			continue
+		}
+		if rule.ToLegGroupId != next.LegGroupId && (rule.ToLegGroupId != "" || hasToGroup) {
+			continue
+		}
+		if transfersInSequence >= transferCountLimit(rule) {
+			continue
+		}
+		if rule.DurationLimit != nil && transferDuration(rule.DurationLimitType, sequenceStart, leg) > *rule.DurationLimit {
+			continue
+		}
+		if best == nil || transferCountLimit(rule) < transferCountLimit(best) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// transferCountLimit returns the number of consecutive transfers the rule can be applied to.
+func transferCountLimit(rule *FareTransferRule) int32 {
+	if rule.TransferCount == nil || *rule.TransferCount < 0 {
+		return math.MaxInt32
+	}
+	return *rule.TransferCount
+}
+
+func transferDuration(durationLimitType DurationLimitType, current, next effectiveFareLeg) time.Duration {
+	switch durationLimitType {
+	case DurationLimitType_DepartureToDeparture:
+		return next.departureTime.Sub(current.departureTime)
+	case DurationLimitType_ArrivalToDeparture:
+		return next.departureTime.Sub(current.arrivalTime)
+	case DurationLimitType_ArrivalToArrival:
+		return next.arrivalTime.Sub(current.arrivalTime)
+	default:
+		return next.arrivalTime.Sub(current.departureTime)
+	}
+}
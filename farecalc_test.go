@@ -0,0 +1,465 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateFare(t *testing.T) {
+	baseFeed := func() *zipBuilder {
+		return newZipBuilder().add(
+			"agency.txt",
+			"agency_id,agency_name,agency_url,agency_timezone",
+			"agency,Agency,https://example.com,America/New_York",
+		).add(
+			"routes.txt",
+			"route_id,route_type,network_id",
+			"route_1,3,network_1",
+			"route_2,3,network_2",
+		).add(
+			"stops.txt",
+			"stop_id,zone_id",
+			"stop_1,zone_1",
+			"stop_2,zone_2",
+			"stop_3,zone_3",
+		).add(
+			"calendar.txt",
+			"service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date",
+			"weekday,1,1,1,1,1,0,0,20220501,20220531",
+		).add(
+			"trips.txt",
+			"route_id,service_id,trip_id",
+			"route_1,weekday,trip_1",
+			"route_2,weekday,trip_2",
+		).add(
+			"stop_times.txt",
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+			"trip_1,stop_1,1,08:00:00,08:00:00",
+			"trip_1,stop_2,2,08:10:00,08:10:00",
+			"trip_1,stop_3,3,08:20:00,08:20:00",
+			"trip_2,stop_3,1,09:00:00,09:00:00",
+			"trip_2,stop_1,2,09:30:00,09:30:00",
+		)
+	}
+	faresV1 := func(transfers string) *zipBuilder {
+		return baseFeed().add(
+			"fare_attributes.txt",
+			"fare_id,price,currency_type,payment_method,transfers,transfer_duration",
+			"local,2.00,USD,0,"+transfers+",3600",
+			"express,5.00,USD,0,0,",
+			"zonal,1.50,USD,0,0,",
+		).add(
+			"fare_rules.txt",
+			"fare_id,route_id,origin_id,destination_id,contains_id",
+			"local,route_1,,,",
+			"local,route_2,,,",
+			"express,route_1,zone_1,zone_3,",
+			"zonal,,,,zone_1",
+			"zonal,,,,zone_2",
+		)
+	}
+	faresV2 := baseFeed().add(
+		"areas.txt",
+		"area_id",
+		"area_1",
+		"area_3",
+	).add(
+		"stop_areas.txt",
+		"area_id,stop_id",
+		"area_1,stop_1",
+		"area_3,stop_3",
+	).add(
+		"timeframes.txt",
+		"timeframe_group_id,start_time,end_time,service_id",
+		"peak,07:00:00,09:00:00,weekday",
+	).add(
+		"fare_products.txt",
+		"fare_product_id,amount,currency",
+		"single,2.75,USD",
+		"peak,3.50,USD",
+		"transfer,0.25,USD",
+		"day_pass,5.00,USD",
+	).add(
+		"fare_leg_rules.txt",
+		"leg_group_id,network_id,from_area_id,to_area_id,from_timeframe_group_id,fare_product_id",
+		"group_1,network_1,,,,single",
+		"group_1,network_1,,,peak,peak",
+		"group_2,network_2,area_3,area_1,,single",
+	).add(
+		"fare_transfer_rules.txt",
+		"from_leg_group_id,to_leg_group_id,duration_limit,duration_limit_type,fare_transfer_type,fare_product_id",
+		"group_1,group_2,3600,1,0,transfer",
+	)
+	faresV2WithTransferProduct := baseFeed().add(
+		"fare_products.txt",
+		"fare_product_id,amount,currency",
+		"single,2.75,USD",
+		"day_pass,5.00,USD",
+	).add(
+		"fare_leg_rules.txt",
+		"leg_group_id,fare_product_id",
+		"group_1,single",
+	).add(
+		"fare_transfer_rules.txt",
+		"from_leg_group_id,to_leg_group_id,transfer_count,fare_transfer_type,fare_product_id",
+		"group_1,group_1,-1,2,day_pass",
+	)
+
+	faresV2WithRules := func(fareLegRules, fareTransferRules []string) *zipBuilder {
+		return baseFeed().add(
+			"areas.txt",
+			"area_id",
+			"area_1",
+		).add(
+			"stop_areas.txt",
+			"area_id,stop_id",
+			"area_1,stop_1",
+		).add(
+			"fare_products.txt",
+			"fare_product_id,amount,currency",
+			"single,2.75,USD",
+			"peak,3.50,USD",
+			"transfer,0.25,USD",
+			"day_pass,5.00,USD",
+		).add(
+			"fare_leg_rules.txt", fareLegRules...,
+		).add(
+			"fare_transfer_rules.txt", fareTransferRules...,
+		)
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %s", err)
+	}
+	// May 4 2022 is a Wednesday.
+	at := func(hour, minute int) time.Time {
+		return time.Date(2022, 5, 4, hour, minute, 0, 0, newYork)
+	}
+	for _, tc := range []struct {
+		desc          string
+		feed          *zipBuilder
+		parseOpts     ParseStaticOptions
+		legs          func(s *Static) []FareLeg
+		expectedTotal float64
+		expectedItems [][]int
+		expectedErr   bool
+	}{
+		{
+			desc: "v1 cheapest fare with free transfer",
+			feed: faresV1("1"),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 2,
+			expectedItems: [][]int{{0, 1}},
+		},
+		{
+			desc: "v1 no transfers allowed",
+			feed: faresV1("0"),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 4,
+			expectedItems: [][]int{{0}, {1}},
+		},
+		{
+			desc: "v1 transfer expired",
+			feed: faresV1("1"),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 30), at(10, 0)},
+				}
+			},
+			expectedTotal: 4,
+			expectedItems: [][]int{{0}, {1}},
+		},
+		{
+			desc: "v1 contains zones",
+			feed: faresV1("1"),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[1], at(8, 0), at(8, 10)},
+				}
+			},
+			expectedTotal: 1.5,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 peak timeframe with transfer",
+			feed: faresV2,
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					// 12:00 UTC is 08:00 in New York.
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], time.Date(2022, 5, 4, 12, 0, 0, 0, time.UTC), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 3.75,
+			expectedItems: [][]int{{0, 1}, {1}},
+		},
+		{
+			desc:      "v2 peak timeframe in overridden timezone",
+			feed:      faresV2,
+			parseOpts: ParseStaticOptions{Timezone: time.UTC},
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					// 08:00 in New York is 12:00 UTC, outside the peak timeframe.
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+				}
+			},
+			expectedTotal: 2.75,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 off peak with transfer",
+			feed: faresV2,
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(10, 0), at(10, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(10, 30), at(11, 0)},
+				}
+			},
+			expectedTotal: 3,
+			expectedItems: [][]int{{0, 1}, {1}},
+		},
+		{
+			desc: "v2 peak timeframe on a weekend",
+			feed: faresV2,
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0).AddDate(0, 0, 3), at(8, 20).AddDate(0, 0, 3)},
+				}
+			},
+			expectedTotal: 2.75,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 transfer outside duration limit",
+			feed: faresV2,
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(10, 0), at(10, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(11, 30), at(12, 0)},
+				}
+			},
+			expectedTotal: 5.5,
+			expectedItems: [][]int{{0}, {1}},
+		},
+		{
+			desc: "v2 transfer product replaces leg costs",
+			feed: faresV2WithTransferProduct,
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(10, 0), at(10, 20)},
+				}
+			},
+			expectedTotal: 5,
+			expectedItems: [][]int{{0, 1, 2}},
+		},
+		{
+			desc: "v2 transfer plus to-leg cost",
+			feed: faresV2WithRules(
+				[]string{"leg_group_id,fare_product_id", "group_1,single"},
+				[]string{
+					"from_leg_group_id,to_leg_group_id,transfer_count,fare_transfer_type,fare_product_id",
+					"group_1,group_1,-1,1,transfer",
+				},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 5.75,
+			expectedItems: [][]int{{0}, {1}, {1}},
+		},
+		{
+			desc: "v2 transfer cost without fare product",
+			feed: faresV2WithRules(
+				[]string{"leg_group_id,fare_product_id", "group_1,single"},
+				[]string{
+					"from_leg_group_id,to_leg_group_id,transfer_count,fare_transfer_type",
+					"group_1,group_1,-1,2",
+				},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 2.75,
+			expectedItems: [][]int{{0, 1}},
+		},
+		{
+			desc: "v2 empty network matches network without rules",
+			feed: faresV2WithRules(
+				[]string{"network_id,from_area_id,fare_product_id", "network_1,area_1,peak", ",,single"},
+				[]string{"fare_transfer_type"},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 2.75,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 specific network takes precedence over empty network",
+			feed: faresV2WithRules(
+				[]string{"network_id,from_area_id,fare_product_id", "network_1,area_1,peak", ",,single"},
+				[]string{"fare_transfer_type"},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+				}
+			},
+			expectedTotal: 3.5,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 empty network does not match network with rules",
+			feed: faresV2WithRules(
+				[]string{"network_id,from_area_id,fare_product_id", "network_1,area_1,peak", ",,single"},
+				[]string{"fare_transfer_type"},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[1], &s.Stops[2], at(8, 10), at(8, 20)},
+				}
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "v2 empty fields match all with rule priorities",
+			feed: faresV2WithRules(
+				[]string{"network_id,from_area_id,fare_product_id,rule_priority", "network_1,,single,0", ",area_1,day_pass,1"},
+				[]string{"fare_transfer_type"},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+				}
+			},
+			expectedTotal: 5,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 empty fields match all with empty rule priorities",
+			feed: faresV2WithRules(
+				[]string{"network_id,from_area_id,fare_product_id,rule_priority", "network_1,,single,", ",area_1,day_pass,"},
+				[]string{"fare_transfer_type"},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+				}
+			},
+			expectedTotal: 2.75,
+			expectedItems: [][]int{{0}},
+		},
+		{
+			desc: "v2 specific leg group takes precedence over empty leg group",
+			feed: faresV2WithRules(
+				[]string{"leg_group_id,network_id,fare_product_id", "group_1,network_1,single", "group_2,network_2,single"},
+				[]string{
+					"from_leg_group_id,to_leg_group_id,fare_transfer_type,fare_product_id",
+					",group_2,0,transfer",
+					"group_1,group_2,0,",
+				},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+				}
+			},
+			expectedTotal: 2.75,
+			expectedItems: [][]int{{0, 1}},
+		},
+		{
+			desc: "v2 smallest transfer count that allows the transfer",
+			feed: faresV2WithRules(
+				[]string{"leg_group_id,fare_product_id", "group_1,single"},
+				[]string{
+					"from_leg_group_id,to_leg_group_id,transfer_count,fare_transfer_type,fare_product_id",
+					"group_1,group_1,-1,0,day_pass",
+					"group_1,group_1,1,0,transfer",
+					"group_1,group_1,2,0,peak",
+				},
+			),
+			legs: func(s *Static) []FareLeg {
+				return []FareLeg{
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(8, 0), at(8, 20)},
+					{&s.Trips[1], &s.Stops[2], &s.Stops[0], at(9, 0), at(9, 30)},
+					{&s.Trips[0], &s.Stops[0], &s.Stops[2], at(10, 0), at(10, 20)},
+				}
+			},
+			expectedTotal: 6.5,
+			expectedItems: [][]int{{0, 1, 2}, {1}, {2}},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			static, err := ParseStatic(tc.feed.build(), tc.parseOpts)
+			if err != nil {
+				t.Fatalf("error when parsing: %s", err)
+			}
+			fare, err := static.CalculateFare(tc.legs(static), FareCalculationOptions{})
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got fare %v", fare)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error when calculating fare: %s", err)
+			}
+			if fare.Total != tc.expectedTotal || fare.Currency != "USD" {
+				t.Errorf("fare total: got %v %s, want %v USD", fare.Total, fare.Currency, tc.expectedTotal)
+			}
+			var items [][]int
+			for _, item := range fare.Items {
+				items = append(items, item.Legs)
+			}
+			if len(items) != len(tc.expectedItems) {
+				t.Fatalf("fare items: got %v, want %v", items, tc.expectedItems)
+			}
+			for i := range items {
+				if len(items[i]) != len(tc.expectedItems[i]) {
+					t.Fatalf("fare items: got %v, want %v", items, tc.expectedItems)
+				}
+				for j := range items[i] {
+					if items[i][j] != tc.expectedItems[i][j] {
+						t.Fatalf("fare items: got %v, want %v", items, tc.expectedItems)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateFare_NoFareData(t *testing.T) {
+	static, err := ParseStatic(newZipBuilderWithDefaults().build(), ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	_, err = static.CalculateFare([]FareLeg{
+		{Trip: &static.Trips[0], From: &static.Stops[0], To: &static.Stops[0]},
+	}, FareCalculationOptions{})
+	if err == nil {
+		t.Errorf("expected an error for a feed with no fare data")
+	}
+}
@@ -0,0 +1,699 @@
+package gtfs
+
+import (
+	"strings"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/csv"
+	"github.com/OneBusAway/go-gtfs/warnings"
+)
+
+// FareAttribute corresponds to a single row in the fare_attributes.txt file.
+type FareAttribute struct {
+	Id            string
+	Price         float64
+	CurrencyType  string
+	PaymentMethod FarePaymentMethod
+	// Number of transfers permitted on this fare. A nil value means unlimited transfers.
+	Transfers *int32
+	Agency    *Agency
+	// Length of time in seconds before a transfer expires.
+	TransferDuration *int32
+}
+
+// FareRule corresponds to a single row in the fare_rules.txt file.
+//
+// Each of the optional fields is nil if the rule does not constrain on that field.
+type FareRule struct {
+	Fare        *FareAttribute
+	Route       *Route
+	Origin      *FareZone
+	Destination *FareZone
+	Contains    *FareZone
+}
+
+// FareZone is a fare zone referenced in the fare_rules.txt file.
+//
+// The stops in the zone are the stops whose zone_id is the ID of the zone.
+type FareZone struct {
+	Id    string
+	Stops []*Stop
+}
+
+func parseFareAttributes(csv *csv.File, ids *StaticIDs) ([]FareAttribute, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("fare_id")
+	priceColumn := csv.RequiredColumn("price")
+	currencyTypeColumn := csv.RequiredColumn("currency_type")
+	paymentMethodColumn := csv.RequiredColumn("payment_method")
+	// An empty value in the transfers column means unlimited transfers, so the column is read as optional.
+	transfersColumn := csv.OptionalColumn("transfers")
+	agencyIDColumn := csv.OptionalColumn("agency_id")
+	transferDurationColumn := csv.OptionalColumn("transfer_duration")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var fareAttributes []FareAttribute
+	for csv.NextRow() {
+		fareID := idColumn.Read()
+		price := priceColumn.Read()
+		currencyType := currencyTypeColumn.Read()
+		paymentMethod := paymentMethodColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		agency, ok := ids.lookupAgency(agencyIDColumn.Read())
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "agency_id", Value: agencyIDColumn.Read()}))
+			continue
+		}
+		parsedPrice := parseFloat64(price)
+		if parsedPrice == nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "price", Value: price}))
+			continue
+		}
+		parsedPaymentMethod, ok := parseFarePaymentMethod(paymentMethod)
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "payment_method", Value: paymentMethod}))
+			continue
+		}
+		fareAttributes = append(fareAttributes, FareAttribute{
+			Id:               fareID,
+			Price:            *parsedPrice,
+			CurrencyType:     currencyType,
+			PaymentMethod:    parsedPaymentMethod,
+			Transfers:        parseInt32(strings.TrimSpace(transfersColumn.Read())),
+			Agency:           agency,
+			TransferDuration: parseInt32(strings.TrimSpace(transferDurationColumn.Read())),
+		})
+	}
+	return fareAttributes, w
+}
+
+func parseFareRules(csv *csv.File, stops []Stop, ids *StaticIDs) ([]FareRule, []FareZone, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	fareIDColumn := csv.RequiredColumn("fare_id")
+	routeIDColumn := csv.OptionalColumn("route_id")
+	originIDColumn := csv.OptionalColumn("origin_id")
+	destinationIDColumn := csv.OptionalColumn("destination_id")
+	containsIDColumn := csv.OptionalColumn("contains_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, nil, warnings
+	}
+
+	// Zones are only linked after all rows have been read, as pointers into the zones slice
+	// are only stable once the slice has been fully built.
+	type rawFareRule struct {
+		fare                                *FareAttribute
+		route                               *Route
+		originID, destinationID, containsID string
+	}
+	var rawRules []rawFareRule
+	var zoneIDs []string
+	zoneIDToIndex := map[string]int{}
+	zoneHasStops := map[string]bool{}
+	for i := range stops {
+		zoneHasStops[stops[i].ZoneId] = true
+	}
+	// The warning for a zone without stops is raised on the first row that references the zone.
+	addZone := func(zoneID string) {
+		if zoneID == "" {
+			return
+		}
+		if _, ok := zoneIDToIndex[zoneID]; ok {
+			return
+		}
+		if !zoneHasStops[zoneID] {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.FareZoneWithoutStops{ZoneID: zoneID}))
+		}
+		zoneIDToIndex[zoneID] = len(zoneIDs)
+		zoneIDs = append(zoneIDs, zoneID)
+	}
+	for csv.NextRow() {
+		fareID := fareIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		fare, ok := ids.fareAttributes[fareID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "fare_id", Value: fareID}))
+			continue
+		}
+		rawRule := rawFareRule{
+			fare:          fare,
+			originID:      originIDColumn.Read(),
+			destinationID: destinationIDColumn.Read(),
+			containsID:    containsIDColumn.Read(),
+		}
+		if routeID := routeIDColumn.Read(); routeID != "" {
+			rawRule.route, ok = ids.routes[routeID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "route_id", Value: routeID}))
+				continue
+			}
+		}
+		addZone(rawRule.originID)
+		addZone(rawRule.destinationID)
+		addZone(rawRule.containsID)
+		rawRules = append(rawRules, rawRule)
+	}
+
+	var zones []FareZone
+	if len(zoneIDs) > 0 {
+		zones = make([]FareZone, len(zoneIDs))
+	}
+	for i, zoneID := range zoneIDs {
+		zones[i].Id = zoneID
+	}
+	for i := range stops {
+		zoneIndex, ok := zoneIDToIndex[stops[i].ZoneId]
+		if !ok {
+			continue
+		}
+		zones[zoneIndex].Stops = append(zones[zoneIndex].Stops, &stops[i])
+	}
+	zoneOrNil := func(zoneID string) *FareZone {
+		if zoneID == "" {
+			return nil
+		}
+		return &zones[zoneIDToIndex[zoneID]]
+	}
+	var rules []FareRule
+	for _, rawRule := range rawRules {
+		rules = append(rules, FareRule{
+			Fare:        rawRule.fare,
+			Route:       rawRule.route,
+			Origin:      zoneOrNil(rawRule.originID),
+			Destination: zoneOrNil(rawRule.destinationID),
+			Contains:    zoneOrNil(rawRule.containsID),
+		})
+	}
+	return rules, zones, w
+}
+
+// RiderCategory corresponds to a single row in the rider_categories.txt file.
+type RiderCategory struct {
+	Id   string
+	Name string
+	// Whether this is the category that should be displayed by default to riders.
+	IsDefault      bool
+	EligibilityUrl string
+}
+
+// FareMedia corresponds to a single row in the fare_media.txt file.
+type FareMedia struct {
+	Id   string
+	Name string
+	Type FareMediaType
+}
+
+// FareProduct describes a fare product defined in the fare_products.txt file.
+//
+// A fare product may be offered at different prices for different rider categories and fare media.
+// Each row in the fare_products.txt file with the product's ID corresponds to one price.
+type FareProduct struct {
+	Id     string
+	Name   string
+	Prices []FareProductPrice
+}
+
+// FareProductPrice is the price of a fare product for a specific rider category and fare media.
+type FareProductPrice struct {
+	// The rider category this price applies to, or nil if the price applies to all rider categories.
+	RiderCategory *RiderCategory
+	// The fare media this price applies to, or nil if the price does not depend on the fare media.
+	Media    *FareMedia
+	Amount   float64
+	Currency string
+}
+
+// Area corresponds to a single area defined in the areas.txt file.
+//
+// The stops in the area are those listed in the stop_areas.txt file.
+type Area struct {
+	Id    string
+	Name  string
+	Stops []*Stop
+}
+
+// Timeframe corresponds to a single row in the timeframes.txt file.
+type Timeframe struct {
+	GroupId   string
+	StartTime time.Duration
+	EndTime   time.Duration
+	Service   *Service
+}
+
+// FareLegRule corresponds to a single row in the fare_leg_rules.txt file.
+//
+// Empty IDs and nil pointers indicate that the rule does not constrain on the associated field.
+type FareLegRule struct {
+	LegGroupId           string
+	NetworkId            string
+	FromArea             *Area
+	ToArea               *Area
+	FromTimeframeGroupId string
+	FromTimeframes       []*Timeframe
+	ToTimeframeGroupId   string
+	ToTimeframes         []*Timeframe
+	FareProduct          *FareProduct
+	RulePriority         *int32
+}
+
+// FareLegJoinRule corresponds to a single row in the fare_leg_join_rules.txt file.
+type FareLegJoinRule struct {
+	FromNetworkId string
+	ToNetworkId   string
+	FromStop      *Stop
+	ToStop        *Stop
+}
+
+// FareTransferRule corresponds to a single row in the fare_transfer_rules.txt file.
+type FareTransferRule struct {
+	FromLegGroupId string
+	ToLegGroupId   string
+	// Number of consecutive transfers allowed. A value of -1 means unlimited transfers.
+	TransferCount     *int32
+	DurationLimit     *time.Duration
+	DurationLimitType DurationLimitType
+	FareTransferType  FareTransferType
+	// The cost of the transfer, or nil if the transfer has no cost.
+	FareProduct *FareProduct
+}
+
+func parseRiderCategories(csv *csv.File) ([]RiderCategory, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("rider_category_id")
+	nameColumn := csv.RequiredColumn("rider_category_name")
+	isDefaultColumn := csv.OptionalColumn("is_default_fare_category")
+	eligibilityUrlColumn := csv.OptionalColumn("eligibility_url")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var riderCategories []RiderCategory
+	for csv.NextRow() {
+		riderCategory := RiderCategory{
+			Id:             idColumn.Read(),
+			Name:           nameColumn.Read(),
+			IsDefault:      isDefaultColumn.Read() == "1",
+			EligibilityUrl: eligibilityUrlColumn.Read(),
+		}
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		riderCategories = append(riderCategories, riderCategory)
+	}
+	return riderCategories, w
+}
+
+func parseFareMedia(csv *csv.File) ([]FareMedia, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("fare_media_id")
+	nameColumn := csv.OptionalColumn("fare_media_name")
+	typeColumn := csv.RequiredColumn("fare_media_type")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var fareMedia []FareMedia
+	for csv.NextRow() {
+		fareMediaID := idColumn.Read()
+		rawType := typeColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		fareMediaType, ok := parseFareMediaType(rawType)
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "fare_media_type", Value: rawType}))
+			continue
+		}
+		fareMedia = append(fareMedia, FareMedia{
+			Id:   fareMediaID,
+			Name: nameColumn.Read(),
+			Type: fareMediaType,
+		})
+	}
+	return fareMedia, w
+}
+
+func parseFareProducts(csv *csv.File, ids *StaticIDs) ([]FareProduct, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("fare_product_id")
+	nameColumn := csv.OptionalColumn("fare_product_name")
+	riderCategoryIDColumn := csv.OptionalColumn("rider_category_id")
+	fareMediaIDColumn := csv.OptionalColumn("fare_media_id")
+	amountColumn := csv.RequiredColumn("amount")
+	currencyColumn := csv.RequiredColumn("currency")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var fareProducts []FareProduct
+	fareProductIDToIndex := map[string]int{}
+	for csv.NextRow() {
+		fareProductID := idColumn.Read()
+		amount := amountColumn.Read()
+		currency := currencyColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		parsedAmount := parseFloat64(amount)
+		if parsedAmount == nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "amount", Value: amount}))
+			continue
+		}
+		price := FareProductPrice{
+			Amount:   *parsedAmount,
+			Currency: currency,
+		}
+		if riderCategoryID := riderCategoryIDColumn.Read(); riderCategoryID != "" {
+			var ok bool
+			price.RiderCategory, ok = ids.riderCategories[riderCategoryID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "rider_category_id", Value: riderCategoryID}))
+				continue
+			}
+		}
+		if fareMediaID := fareMediaIDColumn.Read(); fareMediaID != "" {
+			var ok bool
+			price.Media, ok = ids.fareMedia[fareMediaID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "fare_media_id", Value: fareMediaID}))
+				continue
+			}
+		}
+		i, ok := fareProductIDToIndex[fareProductID]
+		if !ok {
+			i = len(fareProducts)
+			fareProductIDToIndex[fareProductID] = i
+			fareProducts = append(fareProducts, FareProduct{Id: fareProductID})
+		}
+		if name := nameColumn.Read(); name != "" {
+			fareProducts[i].Name = name
+		}
+		fareProducts[i].Prices = append(fareProducts[i].Prices, price)
+	}
+	return fareProducts, w
+}
+
+func parseAreas(csv *csv.File) ([]Area, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("area_id")
+	nameColumn := csv.OptionalColumn("area_name")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var areas []Area
+	for csv.NextRow() {
+		area := Area{
+			Id:   idColumn.Read(),
+			Name: nameColumn.Read(),
+		}
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		areas = append(areas, area)
+	}
+	return areas, w
+}
+
+func parseStopAreas(csv *csv.File, ids *StaticIDs) []warnings.StaticWarning {
+	var w []warnings.StaticWarning
+	areaIDColumn := csv.RequiredColumn("area_id")
+	stopIDColumn := csv.RequiredColumn("stop_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return warnings
+	}
+
+	for csv.NextRow() {
+		areaID := areaIDColumn.Read()
+		stopID := stopIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		area, ok := ids.areas[areaID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "area_id", Value: areaID}))
+			continue
+		}
+		stop, ok := ids.stops[stopID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "stop_id", Value: stopID}))
+			continue
+		}
+		area.Stops = append(area.Stops, stop)
+	}
+	return w
+}
+
+func parseTimeframes(csv *csv.File, ids *StaticIDs) ([]Timeframe, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	groupIDColumn := csv.RequiredColumn("timeframe_group_id")
+	startTimeColumn := csv.OptionalColumn("start_time")
+	endTimeColumn := csv.OptionalColumn("end_time")
+	serviceIDColumn := csv.RequiredColumn("service_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var timeframes []Timeframe
+	for csv.NextRow() {
+		groupID := groupIDColumn.Read()
+		serviceID := serviceIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		service, ok := ids.services[serviceID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "service_id", Value: serviceID}))
+			continue
+		}
+		// If both the start and end times are empty the timeframe covers the full day.
+		startTime, endTime := time.Duration(0), 24*time.Hour
+		rawStartTime, rawEndTime := startTimeColumn.Read(), endTimeColumn.Read()
+		if rawStartTime != "" || rawEndTime != "" {
+			var startOk, endOk bool
+			startTime, startOk = parseGtfsTimeToDuration(rawStartTime)
+			endTime, endOk = parseGtfsTimeToDuration(rawEndTime)
+			if !startOk {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "start_time", Value: rawStartTime}))
+				continue
+			}
+			if !endOk {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "end_time", Value: rawEndTime}))
+				continue
+			}
+		}
+		timeframes = append(timeframes, Timeframe{
+			GroupId:   groupID,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Service:   service,
+		})
+	}
+	return timeframes, w
+}
+
+func parseRouteNetworks(csv *csv.File, ids *StaticIDs) []warnings.StaticWarning {
+	var w []warnings.StaticWarning
+	networkIDColumn := csv.RequiredColumn("network_id")
+	routeIDColumn := csv.RequiredColumn("route_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return warnings
+	}
+
+	for csv.NextRow() {
+		networkID := networkIDColumn.Read()
+		routeID := routeIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		route, ok := ids.routes[routeID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "route_id", Value: routeID}))
+			continue
+		}
+		route.NetworkId = networkID
+	}
+	return w
+}
+
+// parseFareLegRules parses the fare leg rules, and returns whether the file has a rule_priority column.
+func parseFareLegRules(csv *csv.File, ids *StaticIDs) ([]FareLegRule, bool, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	legGroupIDColumn := csv.OptionalColumn("leg_group_id")
+	networkIDColumn := csv.OptionalColumn("network_id")
+	fromAreaIDColumn := csv.OptionalColumn("from_area_id")
+	toAreaIDColumn := csv.OptionalColumn("to_area_id")
+	fromTimeframeGroupIDColumn := csv.OptionalColumn("from_timeframe_group_id")
+	toTimeframeGroupIDColumn := csv.OptionalColumn("to_timeframe_group_id")
+	fareProductIDColumn := csv.RequiredColumn("fare_product_id")
+	rulePriorityColumn := csv.OptionalColumn("rule_priority")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, false, warnings
+	}
+
+	var rules []FareLegRule
+	for csv.NextRow() {
+		fareProductID := fareProductIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		rule := FareLegRule{
+			LegGroupId:           legGroupIDColumn.Read(),
+			NetworkId:            networkIDColumn.Read(),
+			FromTimeframeGroupId: fromTimeframeGroupIDColumn.Read(),
+			ToTimeframeGroupId:   toTimeframeGroupIDColumn.Read(),
+			RulePriority:         parseInt32(rulePriorityColumn.Read()),
+		}
+		var ok bool
+		rule.FareProduct, ok = ids.fareProducts[fareProductID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "fare_product_id", Value: fareProductID}))
+			continue
+		}
+		if fromAreaID := fromAreaIDColumn.Read(); fromAreaID != "" {
+			rule.FromArea, ok = ids.areas[fromAreaID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "from_area_id", Value: fromAreaID}))
+				continue
+			}
+		}
+		if toAreaID := toAreaIDColumn.Read(); toAreaID != "" {
+			rule.ToArea, ok = ids.areas[toAreaID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "to_area_id", Value: toAreaID}))
+				continue
+			}
+		}
+		if rule.FromTimeframeGroupId != "" {
+			rule.FromTimeframes, ok = ids.timeframeGroups[rule.FromTimeframeGroupId]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "from_timeframe_group_id", Value: rule.FromTimeframeGroupId}))
+				continue
+			}
+		}
+		if rule.ToTimeframeGroupId != "" {
+			rule.ToTimeframes, ok = ids.timeframeGroups[rule.ToTimeframeGroupId]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "to_timeframe_group_id", Value: rule.ToTimeframeGroupId}))
+				continue
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rulePriorityColumn.Present(), w
+}
+
+func parseFareLegJoinRules(csv *csv.File, ids *StaticIDs) ([]FareLegJoinRule, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	fromNetworkIDColumn := csv.RequiredColumn("from_network_id")
+	toNetworkIDColumn := csv.RequiredColumn("to_network_id")
+	fromStopIDColumn := csv.OptionalColumn("from_stop_id")
+	toStopIDColumn := csv.OptionalColumn("to_stop_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var rules []FareLegJoinRule
+	for csv.NextRow() {
+		rule := FareLegJoinRule{
+			FromNetworkId: fromNetworkIDColumn.Read(),
+			ToNetworkId:   toNetworkIDColumn.Read(),
+		}
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		var ok bool
+		if fromStopID := fromStopIDColumn.Read(); fromStopID != "" {
+			rule.FromStop, ok = ids.stops[fromStopID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "from_stop_id", Value: fromStopID}))
+				continue
+			}
+		}
+		if toStopID := toStopIDColumn.Read(); toStopID != "" {
+			rule.ToStop, ok = ids.stops[toStopID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "to_stop_id", Value: toStopID}))
+				continue
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, w
+}
+
+func parseFareTransferRules(csv *csv.File, ids *StaticIDs) ([]FareTransferRule, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	fromLegGroupIDColumn := csv.OptionalColumn("from_leg_group_id")
+	toLegGroupIDColumn := csv.OptionalColumn("to_leg_group_id")
+	transferCountColumn := csv.OptionalColumn("transfer_count")
+	durationLimitColumn := csv.OptionalColumn("duration_limit")
+	durationLimitTypeColumn := csv.OptionalColumn("duration_limit_type")
+	fareTransferTypeColumn := csv.RequiredColumn("fare_transfer_type")
+	fareProductIDColumn := csv.OptionalColumn("fare_product_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var rules []FareTransferRule
+	for csv.NextRow() {
+		rawFareTransferType := fareTransferTypeColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		fareTransferType, ok := parseFareTransferType(rawFareTransferType)
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "fare_transfer_type", Value: rawFareTransferType}))
+			continue
+		}
+		rule := FareTransferRule{
+			FromLegGroupId:    fromLegGroupIDColumn.Read(),
+			ToLegGroupId:      toLegGroupIDColumn.Read(),
+			TransferCount:     parseInt32(transferCountColumn.Read()),
+			DurationLimitType: parseDurationLimitType(durationLimitTypeColumn.Read()),
+			FareTransferType:  fareTransferType,
+		}
+		if durationLimit := parseInt32(durationLimitColumn.Read()); durationLimit != nil {
+			d := time.Duration(*durationLimit) * time.Second
+			rule.DurationLimit = &d
+		}
+		if fareProductID := fareProductIDColumn.Read(); fareProductID != "" {
+			rule.FareProduct, ok = ids.fareProducts[fareProductID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "fare_product_id", Value: fareProductID}))
+				continue
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, w
+}
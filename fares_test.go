@@ -0,0 +1,338 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+	"github.com/OneBusAway/go-gtfs/warnings"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFaresV1(t *testing.T) {
+	agency := Agency{
+		Id:       "a",
+		Name:     "b",
+		Url:      "c",
+		Timezone: "d",
+	}
+	route := Route{
+		Id:                "route_id",
+		Agency:            &agency,
+		Color:             "FFFFFF",
+		TextColor:         "000000",
+		Type:              RouteType_Bus,
+		ContinuousPickup:  PickupDropOffPolicy_No,
+		ContinuousDropOff: PickupDropOffPolicy_No,
+	}
+	stop1 := Stop{Id: "stop_1", ZoneId: "zone_1"}
+	stop2 := Stop{Id: "stop_2", ZoneId: "zone_2"}
+	stop3 := Stop{Id: "stop_3", ZoneId: "zone_2"}
+	zone1 := FareZone{Id: "zone_1", Stops: []*Stop{&stop1}}
+	zone2 := FareZone{Id: "zone_2", Stops: []*Stop{&stop2, &stop3}}
+	for _, tc := range []struct {
+		desc                   string
+		fareAttributesContent  string
+		fareRulesContent       string
+		expectedFareAttributes []FareAttribute
+		expectedFareRules      []FareRule
+		expectedFareZones      []FareZone
+	}{
+		{
+			desc: "fare attributes with only required fields",
+			fareAttributesContent: "fare_id,price,currency_type,payment_method,transfers\n" +
+				"fare_1,2.75,USD,0,",
+			expectedFareAttributes: []FareAttribute{
+				{
+					Id:            "fare_1",
+					Price:         2.75,
+					CurrencyType:  "USD",
+					PaymentMethod: FarePaymentMethod_OnBoard,
+					Agency:        &agency,
+				},
+			},
+		},
+		{
+			desc: "fare attributes with all fields",
+			fareAttributesContent: "fare_id,price,currency_type,payment_method,transfers,agency_id,transfer_duration\n" +
+				"fare_1,2.75,USD,1,2,a,7200",
+			expectedFareAttributes: []FareAttribute{
+				{
+					Id:               "fare_1",
+					Price:            2.75,
+					CurrencyType:     "USD",
+					PaymentMethod:    FarePaymentMethod_BeforeBoarding,
+					Transfers:        ptr(int32(2)),
+					Agency:           &agency,
+					TransferDuration: ptr(int32(7200)),
+				},
+			},
+		},
+		{
+			desc: "fare attributes with invalid values",
+			fareAttributesContent: "fare_id,price,currency_type,payment_method,transfers,agency_id\n" +
+				"fare_1,free,USD,0,,\n" +
+				"fare_2,1,USD,3,,\n" +
+				"fare_3,1,USD,0,,unknown_agency\n" +
+				"fare_4,1,,0,,",
+		},
+		{
+			desc: "fare rules",
+			fareAttributesContent: "fare_id,price,currency_type,payment_method,transfers\n" +
+				"fare_1,2.75,USD,0,",
+			fareRulesContent: "fare_id,route_id,origin_id,destination_id,contains_id\n" +
+				"fare_1,route_id,zone_1,zone_2,\n" +
+				"fare_1,,,,zone_2\n" +
+				"fare_1,unknown_route,,,\n" +
+				"unknown_fare,,,,",
+			expectedFareAttributes: []FareAttribute{
+				{
+					Id:            "fare_1",
+					Price:         2.75,
+					CurrencyType:  "USD",
+					PaymentMethod: FarePaymentMethod_OnBoard,
+					Agency:        &agency,
+				},
+			},
+			expectedFareRules: []FareRule{
+				{
+					Fare: &FareAttribute{
+						Id:            "fare_1",
+						Price:         2.75,
+						CurrencyType:  "USD",
+						PaymentMethod: FarePaymentMethod_OnBoard,
+						Agency:        &agency,
+					},
+					Route:       &route,
+					Origin:      &zone1,
+					Destination: &zone2,
+				},
+				{
+					Fare: &FareAttribute{
+						Id:            "fare_1",
+						Price:         2.75,
+						CurrencyType:  "USD",
+						PaymentMethod: FarePaymentMethod_OnBoard,
+						Agency:        &agency,
+					},
+					Contains: &zone2,
+				},
+			},
+			expectedFareZones: []FareZone{zone1, zone2},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			builder := newZipBuilderWithDefaults().add(
+				"stops.txt",
+				"stop_id,zone_id",
+				"stop_1,zone_1",
+				"stop_2,zone_2",
+				"stop_3,zone_2",
+			).add("fare_attributes.txt", tc.fareAttributesContent)
+			if tc.fareRulesContent != "" {
+				builder.add("fare_rules.txt", tc.fareRulesContent)
+			}
+
+			actual, err := ParseStatic(builder.build(), ParseStaticOptions{})
+			if err != nil {
+				t.Fatalf("error when parsing: %s", err)
+			}
+			if diff := cmp.Diff(actual.FareAttributes, tc.expectedFareAttributes); diff != "" {
+				t.Errorf("fare attributes not the same: %s", diff)
+			}
+			if diff := cmp.Diff(actual.FareRules, tc.expectedFareRules); diff != "" {
+				t.Errorf("fare rules not the same: %s", diff)
+			}
+			if diff := cmp.Diff(actual.FareZones, tc.expectedFareZones); diff != "" {
+				t.Errorf("fare zones not the same: %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseFaresV1_ZoneWithoutStops(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id,zone_id",
+		"stop_1,zone_1",
+	).add(
+		"fare_attributes.txt",
+		"fare_id,price,currency_type,payment_method,transfers",
+		"fare_1,2.75,USD,0,",
+	).add(
+		"fare_rules.txt",
+		"fare_id,origin_id,destination_id",
+		"fare_1,zone_1,zone_1",
+		"fare_1,zone_1,zone_2",
+		"fare_1,zone_2,zone_1",
+	).build()
+
+	actual, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	if diff := cmp.Diff(actual.Warnings, []warnings.StaticWarning{
+		{
+			Kind:          warnings.FareZoneWithoutStops{ZoneID: "zone_2"},
+			File:          constants.FareRulesFile,
+			RowNumber:     2,
+			RowContent:    []string{"fare_1", "zone_1", "zone_2"},
+			HeaderContent: []string{"fare_id", "origin_id", "destination_id"},
+		},
+	}); diff != "" {
+		t.Errorf("warnings not the same: %s", diff)
+	}
+	if len(actual.FareZones) != 2 || len(actual.FareZones[1].Stops) != 0 {
+		t.Errorf("got fare zones %v, want zone_1 and zone_2 without stops", actual.FareZones)
+	}
+}
+
+func TestParseFaresV2(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id",
+		"stop_1",
+		"stop_2",
+	).add(
+		"route_networks.txt",
+		"network_id,route_id",
+		"network_1,route_id",
+	).add(
+		"areas.txt",
+		"area_id,area_name",
+		"area_1,Downtown",
+		"area_2,Uptown",
+	).add(
+		"stop_areas.txt",
+		"area_id,stop_id",
+		"area_1,stop_1",
+		"area_2,stop_2",
+		"area_2,unknown_stop",
+	).add(
+		"timeframes.txt",
+		"timeframe_group_id,start_time,end_time,service_id",
+		"peak,07:00:00,09:00:00,service_id",
+		"all_day,,,service_id",
+	).add(
+		"rider_categories.txt",
+		"rider_category_id,rider_category_name,is_default_fare_category,eligibility_url",
+		"adult,Adult,1,",
+		"senior,Senior,0,https://example.com",
+	).add(
+		"fare_media.txt",
+		"fare_media_id,fare_media_name,fare_media_type",
+		"card,Transit card,2",
+	).add(
+		"fare_products.txt",
+		"fare_product_id,fare_product_name,rider_category_id,fare_media_id,amount,currency",
+		"single,Single ride,adult,card,2.75,USD",
+		"single,Single ride,senior,card,1.35,USD",
+		"transfer,,,,0.50,USD",
+	).add(
+		"fare_leg_rules.txt",
+		"leg_group_id,network_id,from_area_id,to_area_id,from_timeframe_group_id,to_timeframe_group_id,fare_product_id,rule_priority",
+		"leg_1,network_1,area_1,area_2,peak,,single,1",
+		"leg_1,network_1,,,,,unknown_product,",
+	).add(
+		"fare_leg_join_rules.txt",
+		"from_network_id,to_network_id,from_stop_id,to_stop_id",
+		"network_1,network_1,stop_1,stop_1",
+	).add(
+		"fare_transfer_rules.txt",
+		"from_leg_group_id,to_leg_group_id,transfer_count,duration_limit,duration_limit_type,fare_transfer_type,fare_product_id",
+		"leg_1,leg_1,2,5400,1,0,transfer",
+		"leg_1,leg_1,,,,5,",
+	).build()
+
+	actual, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	stop1 := Stop{Id: "stop_1"}
+	stop2 := Stop{Id: "stop_2"}
+	area1 := Area{Id: "area_1", Name: "Downtown", Stops: []*Stop{&stop1}}
+	area2 := Area{Id: "area_2", Name: "Uptown", Stops: []*Stop{&stop2}}
+	service := Service{Id: "service_id", StartDate: may4, EndDate: may7}
+	peak := Timeframe{GroupId: "peak", StartTime: 7 * time.Hour, EndTime: 9 * time.Hour, Service: &service}
+	allDay := Timeframe{GroupId: "all_day", StartTime: 0, EndTime: 24 * time.Hour, Service: &service}
+	adult := RiderCategory{Id: "adult", Name: "Adult", IsDefault: true}
+	senior := RiderCategory{Id: "senior", Name: "Senior", EligibilityUrl: "https://example.com"}
+	card := FareMedia{Id: "card", Name: "Transit card", Type: FareMediaType_TransitCard}
+	single := FareProduct{
+		Id:   "single",
+		Name: "Single ride",
+		Prices: []FareProductPrice{
+			{RiderCategory: &adult, Media: &card, Amount: 2.75, Currency: "USD"},
+			{RiderCategory: &senior, Media: &card, Amount: 1.35, Currency: "USD"},
+		},
+	}
+	transfer := FareProduct{
+		Id: "transfer",
+		Prices: []FareProductPrice{
+			{Amount: 0.5, Currency: "USD"},
+		},
+	}
+
+	if got := actual.Routes[0].NetworkId; got != "network_1" {
+		t.Errorf("route network ID: got %q, want %q", got, "network_1")
+	}
+	for _, c := range []struct {
+		name     string
+		actual   any
+		expected any
+	}{
+		{"areas", actual.Areas, []Area{area1, area2}},
+		{"timeframes", actual.Timeframes, []Timeframe{peak, allDay}},
+		{"rider categories", actual.RiderCategories, []RiderCategory{adult, senior}},
+		{"fare media", actual.FareMedia, []FareMedia{card}},
+		{"fare products", actual.FareProducts, []FareProduct{single, transfer}},
+		{
+			"fare leg rules",
+			actual.FareLegRules,
+			[]FareLegRule{
+				{
+					LegGroupId:           "leg_1",
+					NetworkId:            "network_1",
+					FromArea:             &area1,
+					ToArea:               &area2,
+					FromTimeframeGroupId: "peak",
+					FromTimeframes:       []*Timeframe{&peak},
+					FareProduct:          &single,
+					RulePriority:         ptr(int32(1)),
+				},
+			},
+		},
+		{
+			"fare leg join rules",
+			actual.FareLegJoinRules,
+			[]FareLegJoinRule{
+				{
+					FromNetworkId: "network_1",
+					ToNetworkId:   "network_1",
+					FromStop:      &stop1,
+					ToStop:        &stop1,
+				},
+			},
+		},
+		{
+			"fare transfer rules",
+			actual.FareTransferRules,
+			[]FareTransferRule{
+				{
+					FromLegGroupId:    "leg_1",
+					ToLegGroupId:      "leg_1",
+					TransferCount:     ptr(int32(2)),
+					DurationLimit:     ptr(90 * time.Minute),
+					DurationLimitType: DurationLimitType_DepartureToDeparture,
+					FareTransferType:  FareTransferType_FromLegPlusTransfer,
+					FareProduct:       &transfer,
+				},
+			},
+		},
+	} {
+		if diff := cmp.Diff(c.actual, c.expected); diff != "" {
+			t.Errorf("%s not the same: %s", c.name, diff)
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package gtfs
+
+import (
+	"strings"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+	"github.com/OneBusAway/go-gtfs/warnings"
+)
+
+// StaticFilter restricts the data parsed from a GTFS static feed.
+//
+// Routes, trips and stop times that do not match the filter are dropped while parsing.
+// When any filter is set, stops and shapes that are not referenced by the remaining trips are then pruned,
+// along with the transfers, pathways and other records that reference pruned stops or trips.
+// Stops in the same station as a referenced stop are kept, so that pathways within the station are preserved.
+// Agencies and services are never pruned.
+type StaticFilter struct {
+	// If non-empty, only routes of these agencies are parsed.
+	AgencyIDs []string
+	// If non-empty, only these routes are parsed.
+	RouteIDs []string
+	// If non-zero, only trips whose service runs on or after this date are parsed.
+	// Only the date part of the time is used.
+	StartDate time.Time
+	// If non-zero, only trips whose service runs on or before this date are parsed.
+	// Only the date part of the time is used.
+	EndDate time.Time
+	// If non-nil, only trips that call at a stop within the bounding box are parsed.
+	//
+	// The stop times of trips outside the bounding box are dropped while stop_times.txt is read, which
+	// requires the rows of each trip to be contiguous as described in ParseStaticOptions.VisitStopTimes.
+	// If the rows of a trip outside the bounding box are followed by rows of other trips, its later rows
+	// are skipped.
+	BoundingBox *BoundingBox
+}
+
+// BoundingBox is a rectangular geographic area.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Contains returns true if the point lies inside the bounding box.
+func (b *BoundingBox) Contains(latitude, longitude float64) bool {
+	return b.MinLatitude <= latitude && latitude <= b.MaxLatitude &&
+		b.MinLongitude <= longitude && longitude <= b.MaxLongitude
+}
+
+func (f *StaticFilter) isActive() bool {
+	return len(f.AgencyIDs) > 0 || len(f.RouteIDs) > 0 || !f.StartDate.IsZero() || !f.EndDate.IsZero() || f.BoundingBox != nil
+}
+
+func (f *StaticFilter) keepRoute(route *Route) bool {
+	if len(f.RouteIDs) > 0 && !containsString(f.RouteIDs, route.Id) {
+		return false
+	}
+	if len(f.AgencyIDs) > 0 && (route.Agency == nil || !containsString(f.AgencyIDs, route.Agency.Id)) {
+		return false
+	}
+	return true
+}
+
+// filterState applies a filter while parsing and records the IDs of the routes and trips it removes,
+// so that references to them in other files are not reported as invalid.
+type filterState struct {
+	filter          *StaticFilter
+	removedRouteIDs map[string]bool
+	removedTripIDs  map[string]bool
+	serviceCache    map[*Service]bool
+	// Trips with a stop time in the bounding box, and trips whose stop times were dropped because
+	// they had none.
+	tripsInBoundingBox      map[*ScheduledTrip]bool
+	tripsOutsideBoundingBox map[*ScheduledTrip]bool
+}
+
+func newFilterState(filter *StaticFilter) *filterState {
+	return &filterState{
+		filter:                  filter,
+		removedRouteIDs:         map[string]bool{},
+		removedTripIDs:          map[string]bool{},
+		serviceCache:            map[*Service]bool{},
+		tripsInBoundingBox:      map[*ScheduledTrip]bool{},
+		tripsOutsideBoundingBox: map[*ScheduledTrip]bool{},
+	}
+}
+
+func (f *filterState) keepRoute(route *Route) bool {
+	if !f.filter.keepRoute(route) {
+		f.removedRouteIDs[route.Id] = true
+		return false
+	}
+	return true
+}
+
+// keepTrip returns true if the trip should be kept.
+// A trip whose route has been removed by the filter is removed, and any other trip without a route is kept
+// so that it can be reported as invalid.
+func (f *filterState) keepTrip(trip *ScheduledTrip, routeID string) bool {
+	if trip.Route == nil && f.removedRouteIDs[routeID] {
+		f.removedTripIDs[trip.ID] = true
+		return false
+	}
+	if trip.Service != nil && !f.keepService(trip.Service) {
+		f.removedTripIDs[trip.ID] = true
+		return false
+	}
+	return true
+}
+
+func (f *filterState) keepService(service *Service) bool {
+	if f.filter.StartDate.IsZero() && f.filter.EndDate.IsZero() {
+		return true
+	}
+	keep, ok := f.serviceCache[service]
+	if !ok {
+		keep = service.runsBetween(f.filter.StartDate, f.filter.EndDate)
+		f.serviceCache[service] = keep
+	}
+	return keep
+}
+
+// addStopTime records whether a stop time of the trip is in the bounding box of the filter.
+func (f *filterState) addStopTime(trip *ScheduledTrip, stopTime *ScheduledStopTime) {
+	if f.filter.BoundingBox == nil || f.tripsInBoundingBox[trip] {
+		return
+	}
+	if stopTimeInBoundingBox(stopTime, f.filter.BoundingBox) {
+		f.tripsInBoundingBox[trip] = true
+	}
+}
+
+// endTripStopTimes is called at the end of each contiguous run of rows of the trip in stop_times.txt.
+// If none of the trip's stop times is in the bounding box of the filter, they are dropped so that
+// trips outside the bounding box do not use memory while the rest of the file is read.
+func (f *filterState) endTripStopTimes(trip *ScheduledTrip) {
+	if f.filter.BoundingBox == nil || f.tripsInBoundingBox[trip] {
+		return
+	}
+	trip.StopTimes = nil
+	f.tripsOutsideBoundingBox[trip] = true
+}
+
+// isOutsideBoundingBox returns true if the stop times of the trip were dropped by endTripStopTimes.
+func (f *filterState) isOutsideBoundingBox(trip *ScheduledTrip) bool {
+	return f.tripsOutsideBoundingBox[trip]
+}
+
+// isRemovedTrip returns true if the trip has been removed by the filter.
+func (f *filterState) isRemovedTrip(tripID string) bool {
+	return f.removedTripIDs[tripID]
+}
+
+// removeWarnings removes the warnings for references to routes and trips removed by the filter.
+func (f *filterState) removeWarnings(w []warnings.StaticWarning) []warnings.StaticWarning {
+	var result []warnings.StaticWarning
+	for _, warning := range w {
+		if reference, ok := warning.Kind.(warnings.InvalidReference); ok {
+			if strings.HasSuffix(reference.Column, "route_id") && f.removedRouteIDs[reference.Value] {
+				continue
+			}
+			if strings.HasSuffix(reference.Column, "trip_id") && f.removedTripIDs[reference.Value] {
+				continue
+			}
+		}
+		result = append(result, warning)
+	}
+	return result
+}
+
+// runsBetween returns true if the service runs on any date between start and end inclusive.
+// Zero times are treated as unbounded.
+func (service *Service) runsBetween(start, end time.Time) bool {
+	toDate := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	from := toDate(service.StartDate)
+	if !start.IsZero() && toDate(start).After(from) {
+		from = toDate(start)
+	}
+	to := toDate(service.EndDate)
+	if !end.IsZero() && toDate(end).Before(to) {
+		to = toDate(end)
+	}
+	for _, d := range service.AddedDates {
+		if d := toDate(d); !d.Before(from) && !d.After(to) && service.runsOn(d) {
+			return true
+		}
+	}
+	// Apart from the added dates, the service runs on days of the week. Each day of the week occurs once more
+	// than the number of removed dates in this many days, so if the service runs on that day of the week at
+	// least one of the occurrences is not removed. This bounds the loop for calendars spanning many years.
+	maxDays := 7 * (len(service.RemovedDates) + 1)
+	for d, i := from, 0; !d.After(to) && i < maxDays; d, i = d.AddDate(0, 0, 1), i+1 {
+		if service.runsOn(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// stopTimeInBoundingBox returns true if the stop, location group or location of the stop time
+// is in the bounding box.
+func stopTimeInBoundingBox(stopTime *ScheduledStopTime, b *BoundingBox) bool {
+	if stopTime.Stop != nil && stopInBoundingBox(stopTime.Stop, b) {
+		return true
+	}
+	if stopTime.LocationGroup != nil {
+		for _, stop := range stopTime.LocationGroup.Stops {
+			if stopInBoundingBox(stop, b) {
+				return true
+			}
+		}
+	}
+	if stopTime.Location != nil {
+		for _, polygon := range stopTime.Location.Polygons {
+			for _, ring := range polygon {
+				for _, point := range ring {
+					if b.Contains(point[1], point[0]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// stopInBoundingBox returns true if the stop is in the bounding box, using the coordinates of the
+// parent station if the stop has none.
+func stopInBoundingBox(stop *Stop, b *BoundingBox) bool {
+	c, ok := stopCoordinates(stop)
+	return ok && b.Contains(c.lat, c.lon)
+}
+
+func containsString(l []string, s string) bool {
+	for _, t := range l {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// stopTimesInBoundingBox returns true if any of the stop times is in the bounding box.
+func stopTimesInBoundingBox(stopTimes []ScheduledStopTime, b *BoundingBox) bool {
+	for i := range stopTimes {
+		if stopTimeInBoundingBox(&stopTimes[i], b) {
+			return true
+		}
+	}
+	return false
+}
+
+// visitedStopTimes records what is needed to prune the feed when stop times are passed to
+// ParseStaticOptions.VisitStopTimes instead of being stored in the trips.
+type visitedStopTimes struct {
+	tripsOutsideBoundingBox map[*ScheduledTrip]bool
+	stopRoots               map[*Stop]bool
+}
+
+func newVisitedStopTimes() *visitedStopTimes {
+	return &visitedStopTimes{
+		tripsOutsideBoundingBox: map[*ScheduledTrip]bool{},
+		stopRoots:               map[*Stop]bool{},
+	}
+}
+
+// add records the stop times of a trip. It returns false if the trip is removed by the filter.
+func (v *visitedStopTimes) add(trip *ScheduledTrip, stopTimes []ScheduledStopTime, filter *StaticFilter) bool {
+	if filter.BoundingBox != nil && !stopTimesInBoundingBox(stopTimes, filter.BoundingBox) {
+		v.tripsOutsideBoundingBox[trip] = true
+		return false
+	}
+	addStopRoots(v.stopRoots, stopTimes)
+	return true
+}
+
+func addStopRoots(roots map[*Stop]bool, stopTimes []ScheduledStopTime) {
+	for i := range stopTimes {
+		if stopTimes[i].Stop != nil {
+			roots[stopTimes[i].Stop.Root()] = true
+		}
+		if stopTimes[i].LocationGroup != nil {
+			for _, stop := range stopTimes[i].LocationGroup.Stops {
+				roots[stop.Root()] = true
+			}
+		}
+	}
+}
+
+// pruneStatic removes trips outside the bounding box of the filter, then removes stops and shapes
+// that are no longer referenced.
+//
+// Stops are only removed if the stop times were parsed, and shapes if the trips were parsed, as otherwise
+// there is nothing that references them.
+// If the stop times were visited rather than stored in the trips, visited must be non-nil.
+func pruneStatic(s *Static, opts *ParseStaticOptions, visited *visitedStopTimes) {
+	filter := &opts.Filter
+	if filter.BoundingBox != nil {
+		pruneTrips(s, func(trip *ScheduledTrip) bool {
+			if visited != nil {
+				return !visited.tripsOutsideBoundingBox[trip]
+			}
+			return stopTimesInBoundingBox(trip.StopTimes, filter.BoundingBox)
+		})
+	}
+	if !opts.skipFile(constants.StopTimesFile) {
+		var stopRoots map[*Stop]bool
+		if visited != nil {
+			stopRoots = visited.stopRoots
+		} else {
+			stopRoots = map[*Stop]bool{}
+			for i := range s.Trips {
+				addStopRoots(stopRoots, s.Trips[i].StopTimes)
+			}
+		}
+		pruneStops(s, stopRoots)
+	}
+	if !opts.skipFile(constants.TripsFile) {
+		pruneShapes(s)
+	}
+}
+
+func pruneTrips(s *Static, keep func(trip *ScheduledTrip) bool) {
+	oldToNew := map[*ScheduledTrip]*ScheduledTrip{}
+	var trips []ScheduledTrip
+	var kept []*ScheduledTrip
+	for i := range s.Trips {
+		if keep(&s.Trips[i]) {
+			trips = append(trips, s.Trips[i])
+			kept = append(kept, &s.Trips[i])
+		}
+	}
+	for i := range kept {
+		oldToNew[kept[i]] = &trips[i]
+		for j := range trips[i].StopTimes {
+			trips[i].StopTimes[j].Trip = &trips[i]
+		}
+	}
+	s.Trips = trips
+
+	var transfers []Transfer
+	for _, transfer := range s.Transfers {
+		if !remapOptional(&transfer.FromTrip, oldToNew) || !remapOptional(&transfer.ToTrip, oldToNew) {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	s.Transfers = transfers
+
+	var attributions []Attribution
+	for _, attribution := range s.Attributions {
+		if !remapOptional(&attribution.Trip, oldToNew) {
+			continue
+		}
+		attributions = append(attributions, attribution)
+	}
+	s.Attributions = attributions
+}
+
+// pruneStops removes stops whose root is not in referencedRoots.
+func pruneStops(s *Static, referencedRoots map[*Stop]bool) {
+	oldToNew := map[*Stop]*Stop{}
+	var stops []Stop
+	var kept []*Stop
+	for i := range s.Stops {
+		if referencedRoots[s.Stops[i].Root()] {
+			stops = append(stops, s.Stops[i])
+			kept = append(kept, &s.Stops[i])
+		}
+	}
+	for i := range kept {
+		oldToNew[kept[i]] = &stops[i]
+	}
+	for i := range stops {
+		remapOptional(&stops[i].Parent, oldToNew)
+	}
+	s.Stops = stops
+
+	for i := range s.Trips {
+		for j := range s.Trips[i].StopTimes {
+			remapOptional(&s.Trips[i].StopTimes[j].Stop, oldToNew)
+		}
+	}
+	var transfers []Transfer
+	for _, transfer := range s.Transfers {
+		if !remapOptional(&transfer.From, oldToNew) || !remapOptional(&transfer.To, oldToNew) {
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+	s.Transfers = transfers
+	var pathways []Pathway
+	for _, pathway := range s.Pathways {
+		if !remapOptional(&pathway.From, oldToNew) || !remapOptional(&pathway.To, oldToNew) {
+			continue
+		}
+		pathways = append(pathways, pathway)
+	}
+	s.Pathways = pathways
+	var fareLegJoinRules []FareLegJoinRule
+	for _, rule := range s.FareLegJoinRules {
+		if !remapOptional(&rule.FromStop, oldToNew) || !remapOptional(&rule.ToStop, oldToNew) {
+			continue
+		}
+		fareLegJoinRules = append(fareLegJoinRules, rule)
+	}
+	s.FareLegJoinRules = fareLegJoinRules
+	for i := range s.FareZones {
+		s.FareZones[i].Stops = remapSlice(s.FareZones[i].Stops, oldToNew)
+	}
+	for i := range s.Areas {
+		s.Areas[i].Stops = remapSlice(s.Areas[i].Stops, oldToNew)
+	}
+	for i := range s.LocationGroups {
+		s.LocationGroups[i].Stops = remapSlice(s.LocationGroups[i].Stops, oldToNew)
+	}
+}
+
+func pruneShapes(s *Static) {
+	referenced := map[*Shape]bool{}
+	for i := range s.Trips {
+		if s.Trips[i].Shape != nil {
+			referenced[s.Trips[i].Shape] = true
+		}
+	}
+	oldToNew := map[*Shape]*Shape{}
+	var shapes []Shape
+	var kept []*Shape
+	for i := range s.Shapes {
+		if referenced[&s.Shapes[i]] {
+			shapes = append(shapes, s.Shapes[i])
+			kept = append(kept, &s.Shapes[i])
+		}
+	}
+	for i := range kept {
+		oldToNew[kept[i]] = &shapes[i]
+	}
+	s.Shapes = shapes
+	for i := range s.Trips {
+		remapOptional(&s.Trips[i].Shape, oldToNew)
+	}
+}
+
+// remapOptional replaces the pointer with its new value.
+// It returns false if the pointer is non-nil and has been pruned.
+func remapOptional[T any](p **T, oldToNew map[*T]*T) bool {
+	if *p == nil {
+		return true
+	}
+	newP, ok := oldToNew[*p]
+	*p = newP
+	return ok
+}
+
+// remapSlice replaces each pointer with its new value, removing pointers that have been pruned.
+func remapSlice[T any](l []*T, oldToNew map[*T]*T) []*T {
+	var result []*T
+	for _, p := range l {
+		if newP, ok := oldToNew[p]; ok {
+			result = append(result, newP)
+		}
+	}
+	return result
+}
@@ -0,0 +1,404 @@
+package gtfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+	"github.com/OneBusAway/go-gtfs/csv"
+	"github.com/OneBusAway/go-gtfs/warnings"
+)
+
+// Location is a zone in which riders can request a pickup or drop off, from the locations.geojson file.
+type Location struct {
+	Id          string
+	Name        string
+	Description string
+	// Polygons making up the zone.
+	//
+	// Each polygon is a list of linear rings. The first ring is the exterior boundary of the polygon
+	// and any further rings are holes in it. Each point is a (longitude, latitude) pair.
+	Polygons [][][][2]float64
+}
+
+// Contains returns true if the point lies inside the zone.
+func (l *Location) Contains(latitude, longitude float64) bool {
+	for _, polygon := range l.Polygons {
+		inside := false
+		for _, ring := range polygon {
+			// Ray casting; holes are handled by the even-odd rule.
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				loni, lati := ring[i][0], ring[i][1]
+				lonj, latj := ring[j][0], ring[j][1]
+				if (lati > latitude) != (latj > latitude) &&
+					longitude < (lonj-loni)*(latitude-lati)/(latj-lati)+loni {
+					inside = !inside
+				}
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+// LocationGroup corresponds to a single row in the location_groups.txt file.
+//
+// A location group is a set of stops at which riders can request a pickup or drop off.
+// The stops are populated from the location_group_stops.txt file.
+type LocationGroup struct {
+	Id    string
+	Name  string
+	Stops []*Stop
+}
+
+// BookingRule corresponds to a single row in the booking_rules.txt file.
+type BookingRule struct {
+	Id   string
+	Type BookingType
+	// Minimum time between when a booking is made and the pickup. Only set for same day bookings.
+	PriorNoticeDurationMin *time.Duration
+	// Maximum time between when a booking is made and the pickup. Only set for same day bookings.
+	PriorNoticeDurationMax *time.Duration
+	// Latest day before the trip on which a booking can be made. Only set for prior day bookings.
+	PriorNoticeLastDay *int32
+	// Latest time on the last day at which a booking can be made.
+	PriorNoticeLastTime *time.Duration
+	// Earliest day before the trip on which a booking can be made.
+	PriorNoticeStartDay *int32
+	// Earliest time on the start day at which a booking can be made.
+	PriorNoticeStartTime *time.Duration
+	// Service whose days are used to count the days in PriorNoticeLastDay and PriorNoticeStartDay.
+	// If nil, calendar days are used.
+	PriorNoticeService *Service
+	Message            string
+	PickupMessage      string
+	DropOffMessage     string
+	PhoneNumber        string
+	InfoUrl            string
+	BookingUrl         string
+}
+
+type geoJSONFeatureCollection struct {
+	Features []struct {
+		Id         string `json:"id"`
+		Properties struct {
+			StopName string `json:"stop_name"`
+			StopDesc string `json:"stop_desc"`
+		} `json:"properties"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+}
+
+// readLocationsFile reads the locations.geojson file, if it is in the feed.
+func readLocationsFile(fsys fs.FS) ([]Location, []warnings.StaticWarning, error) {
+	content, err := fsys.Open(string(constants.LocationsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %q: %w", constants.LocationsFile, err)
+	}
+	defer content.Close()
+	locations, w := parseLocations(content)
+	return locations, w, nil
+}
+
+// parseLocations parses the content of the locations.geojson file.
+//
+// If the content cannot be decoded, no locations are returned and a fatal warning is raised.
+func parseLocations(r io.Reader) ([]Location, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	warn := func(kind warnings.StaticWarningKind) {
+		w = append(w, warnings.StaticWarning{Kind: kind, File: constants.LocationsFile})
+	}
+	var collection geoJSONFeatureCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		warn(warnings.InvalidFile{Reason: err.Error()})
+		return nil, w
+	}
+	var locations []Location
+	for _, feature := range collection.Features {
+		if feature.Id == "" {
+			warn(warnings.MissingValues{Columns: []string{"id"}})
+			continue
+		}
+		var rawPolygons [][][][]float64
+		var err error
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var rawPolygon [][][]float64
+			err = json.Unmarshal(feature.Geometry.Coordinates, &rawPolygon)
+			rawPolygons = [][][][]float64{rawPolygon}
+		case "MultiPolygon":
+			err = json.Unmarshal(feature.Geometry.Coordinates, &rawPolygons)
+		default:
+			err = fmt.Errorf("unsupported geometry type %q", feature.Geometry.Type)
+		}
+		if err != nil {
+			warn(warnings.InvalidLocation{LocationID: feature.Id, Reason: err.Error()})
+			continue
+		}
+		location := Location{
+			Id:          feature.Id,
+			Name:        feature.Properties.StopName,
+			Description: feature.Properties.StopDesc,
+		}
+		valid := true
+		for _, rawPolygon := range rawPolygons {
+			var polygon [][][2]float64
+			for _, rawRing := range rawPolygon {
+				var ring [][2]float64
+				for _, rawPoint := range rawRing {
+					if len(rawPoint) < 2 {
+						valid = false
+						break
+					}
+					ring = append(ring, [2]float64{rawPoint[0], rawPoint[1]})
+				}
+				polygon = append(polygon, ring)
+			}
+			location.Polygons = append(location.Polygons, polygon)
+		}
+		if !valid {
+			warn(warnings.InvalidLocation{LocationID: feature.Id, Reason: "positions must have two coordinates"})
+			continue
+		}
+		locations = append(locations, location)
+	}
+	return locations, w
+}
+
+func parseLocationGroups(csv *csv.File) ([]LocationGroup, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("location_group_id")
+	nameColumn := csv.OptionalColumn("location_group_name")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var locationGroups []LocationGroup
+	for csv.NextRow() {
+		locationGroupID := idColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		locationGroups = append(locationGroups, LocationGroup{
+			Id:   locationGroupID,
+			Name: nameColumn.Read(),
+		})
+	}
+	return locationGroups, w
+}
+
+func parseLocationGroupStops(csv *csv.File, ids *StaticIDs) []warnings.StaticWarning {
+	var w []warnings.StaticWarning
+	locationGroupIDColumn := csv.RequiredColumn("location_group_id")
+	stopIDColumn := csv.RequiredColumn("stop_id")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return warnings
+	}
+
+	for csv.NextRow() {
+		locationGroupID := locationGroupIDColumn.Read()
+		stopID := stopIDColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		locationGroup, ok := ids.locationGroups[locationGroupID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "location_group_id", Value: locationGroupID}))
+			continue
+		}
+		stop, ok := ids.stops[stopID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "stop_id", Value: stopID}))
+			continue
+		}
+		locationGroup.Stops = append(locationGroup.Stops, stop)
+	}
+	return w
+}
+
+func parseBookingRules(csv *csv.File, ids *StaticIDs) ([]BookingRule, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("booking_rule_id")
+	typeColumn := csv.RequiredColumn("booking_type")
+	priorNoticeDurationMinColumn := csv.OptionalColumn("prior_notice_duration_min")
+	priorNoticeDurationMaxColumn := csv.OptionalColumn("prior_notice_duration_max")
+	priorNoticeLastDayColumn := csv.OptionalColumn("prior_notice_last_day")
+	priorNoticeLastTimeColumn := csv.OptionalColumn("prior_notice_last_time")
+	priorNoticeStartDayColumn := csv.OptionalColumn("prior_notice_start_day")
+	priorNoticeStartTimeColumn := csv.OptionalColumn("prior_notice_start_time")
+	priorNoticeServiceIDColumn := csv.OptionalColumn("prior_notice_service_id")
+	messageColumn := csv.OptionalColumn("message")
+	pickupMessageColumn := csv.OptionalColumn("pickup_message")
+	dropOffMessageColumn := csv.OptionalColumn("drop_off_message")
+	phoneNumberColumn := csv.OptionalColumn("phone_number")
+	infoUrlColumn := csv.OptionalColumn("info_url")
+	bookingUrlColumn := csv.OptionalColumn("booking_url")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	parseMinutes := func(s string) *time.Duration {
+		minutes := parseInt32(s)
+		if minutes == nil {
+			return nil
+		}
+		d := time.Duration(*minutes) * time.Minute
+		return &d
+	}
+	parseTimeOfDay := func(s string) *time.Duration {
+		d, ok := parseGtfsTimeToDuration(s)
+		if !ok {
+			return nil
+		}
+		return &d
+	}
+	var bookingRules []BookingRule
+	for csv.NextRow() {
+		bookingRuleID := idColumn.Read()
+		rawType := typeColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		bookingType, ok := parseBookingType(rawType)
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "booking_type", Value: rawType}))
+			continue
+		}
+		bookingRule := BookingRule{
+			Id:                     bookingRuleID,
+			Type:                   bookingType,
+			PriorNoticeDurationMin: parseMinutes(priorNoticeDurationMinColumn.Read()),
+			PriorNoticeDurationMax: parseMinutes(priorNoticeDurationMaxColumn.Read()),
+			PriorNoticeLastDay:     parseInt32(priorNoticeLastDayColumn.Read()),
+			PriorNoticeLastTime:    parseTimeOfDay(priorNoticeLastTimeColumn.Read()),
+			PriorNoticeStartDay:    parseInt32(priorNoticeStartDayColumn.Read()),
+			PriorNoticeStartTime:   parseTimeOfDay(priorNoticeStartTimeColumn.Read()),
+			Message:                messageColumn.Read(),
+			PickupMessage:          pickupMessageColumn.Read(),
+			DropOffMessage:         dropOffMessageColumn.Read(),
+			PhoneNumber:            phoneNumberColumn.Read(),
+			InfoUrl:                infoUrlColumn.Read(),
+			BookingUrl:             bookingUrlColumn.Read(),
+		}
+		if serviceID := priorNoticeServiceIDColumn.Read(); serviceID != "" {
+			bookingRule.PriorNoticeService, ok = ids.services[serviceID]
+			if !ok {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "prior_notice_service_id", Value: serviceID}))
+				continue
+			}
+		}
+		bookingRules = append(bookingRules, bookingRule)
+	}
+	return bookingRules, w
+}
+
+// flexStopTimeFields contains the GTFS-Flex columns of the stop_times.txt file.
+type flexStopTimeFields struct {
+	locationGroupIDColumn          csv.OptionalColumn
+	locationIDColumn               csv.OptionalColumn
+	startPickupDropOffWindowColumn csv.OptionalColumn
+	endPickupDropOffWindowColumn   csv.OptionalColumn
+	pickupBookingRuleIDColumn      csv.OptionalColumn
+	dropOffBookingRuleIDColumn     csv.OptionalColumn
+	ids                            *StaticIDs
+	hasStopOrLocationColumn        bool
+}
+
+func newFlexStopTimeFields(f *csv.File, ids *StaticIDs) *flexStopTimeFields {
+	fields := &flexStopTimeFields{
+		locationGroupIDColumn:          f.OptionalColumn("location_group_id"),
+		locationIDColumn:               f.OptionalColumn("location_id"),
+		startPickupDropOffWindowColumn: f.OptionalColumn("start_pickup_drop_off_window"),
+		endPickupDropOffWindowColumn:   f.OptionalColumn("end_pickup_drop_off_window"),
+		pickupBookingRuleIDColumn:      f.OptionalColumn("pickup_booking_rule_id"),
+		dropOffBookingRuleIDColumn:     f.OptionalColumn("drop_off_booking_rule_id"),
+		ids:                            ids,
+	}
+	for _, header := range f.HeaderContent() {
+		if header == "stop_id" || header == "location_group_id" || header == "location_id" {
+			fields.hasStopOrLocationColumn = true
+		}
+	}
+	return fields
+}
+
+// read populates the GTFS-Flex fields of the stop time from the current row, whose stop must already be set.
+//
+// It returns a warning if the row does not have exactly one of a stop, location group and location, or if it
+// references a location group, location or booking rule that does not exist.
+func (fields *flexStopTimeFields) read(stopTime *ScheduledStopTime) warnings.StaticWarningKind {
+	locationGroupID := fields.locationGroupIDColumn.Read()
+	locationID := fields.locationIDColumn.Read()
+	var numSet int
+	for _, set := range []bool{stopTime.Stop != nil, locationGroupID != "", locationID != ""} {
+		if set {
+			numSet++
+		}
+	}
+	switch {
+	case numSet == 0:
+		return warnings.MissingOneOfValues{Columns: []string{"stop_id", "location_group_id", "location_id"}}
+	case numSet > 1:
+		return warnings.ConflictingValues{Columns: []string{"stop_id", "location_group_id", "location_id"}}
+	}
+	if locationGroupID != "" {
+		stopTime.LocationGroup = fields.ids.locationGroups[locationGroupID]
+		if stopTime.LocationGroup == nil {
+			return warnings.InvalidReference{Column: "location_group_id", Value: locationGroupID}
+		}
+	}
+	if locationID != "" {
+		stopTime.Location = fields.ids.locations[locationID]
+		if stopTime.Location == nil {
+			return warnings.InvalidReference{Column: "location_id", Value: locationID}
+		}
+	}
+	if start, ok := parseGtfsTimeToDuration(fields.startPickupDropOffWindowColumn.Read()); ok {
+		stopTime.StartPickupDropOffWindow = &start
+	}
+	if end, ok := parseGtfsTimeToDuration(fields.endPickupDropOffWindowColumn.Read()); ok {
+		stopTime.EndPickupDropOffWindow = &end
+	}
+	for _, bookingRule := range []struct {
+		column csv.OptionalColumn
+		name   string
+		field  **BookingRule
+	}{
+		{fields.pickupBookingRuleIDColumn, "pickup_booking_rule_id", &stopTime.PickupBookingRule},
+		{fields.dropOffBookingRuleIDColumn, "drop_off_booking_rule_id", &stopTime.DropOffBookingRule},
+	} {
+		id := bookingRule.column.Read()
+		if id == "" {
+			continue
+		}
+		*bookingRule.field = fields.ids.bookingRules[id]
+		if *bookingRule.field == nil {
+			return warnings.InvalidReference{Column: bookingRule.name, Value: id}
+		}
+	}
+	return nil
+}
+
+// HasPickupDropOffWindow returns true if the stop time has a GTFS-Flex pickup and drop off window
+// instead of arrival and departure times.
+func (stopTime *ScheduledStopTime) HasPickupDropOffWindow() bool {
+	return stopTime.StartPickupDropOffWindow != nil && stopTime.EndPickupDropOffWindow != nil
+}
@@ -0,0 +1,268 @@
+package gtfs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/warnings"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseFlex(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id",
+		"stop_1",
+		"stop_2",
+	).add(
+		"locations.geojson",
+		`{
+			"type": "FeatureCollection",
+			"features": [
+				{
+					"type": "Feature",
+					"id": "zone",
+					"properties": {"stop_name": "Downtown", "stop_desc": "Downtown zone"},
+					"geometry": {
+						"type": "Polygon",
+						"coordinates": [[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]], [[4, 4], [6, 4], [6, 6], [4, 6], [4, 4]]]
+					}
+				},
+				{
+					"type": "Feature",
+					"id": "multi",
+					"properties": {},
+					"geometry": {
+						"type": "MultiPolygon",
+						"coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]], [[[5, 5], [6, 5], [6, 6], [5, 5]]]]
+					}
+				},
+				{
+					"type": "Feature",
+					"id": "point",
+					"properties": {},
+					"geometry": {"type": "Point", "coordinates": [0, 0]}
+				}
+			]
+		}`,
+	).add(
+		"location_groups.txt",
+		"location_group_id,location_group_name",
+		"group,Group",
+	).add(
+		"location_group_stops.txt",
+		"location_group_id,stop_id",
+		"group,stop_1",
+		"group,stop_2",
+		"group,unknown",
+		"unknown,stop_1",
+	).add(
+		"booking_rules.txt",
+		"booking_rule_id,booking_type,prior_notice_duration_min,prior_notice_duration_max,prior_notice_last_day,"+
+			"prior_notice_last_time,prior_notice_service_id,message,phone_number",
+		"same_day,1,30,120,,,,Call ahead,555-0100",
+		"prior_day,2,,,1,17:00:00,service_id,,",
+		"invalid_type,3,,,,,,,",
+		"invalid_service,2,,,1,17:00:00,unknown,,",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_sequence,stop_id,location_group_id,location_id,arrival_time,departure_time,"+
+			"start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_booking_rule_id,drop_off_booking_rule_id",
+		"trip_id,1,stop_1,,,08:00:00,08:00:00,,,,",
+		"trip_id,2,,,zone,,,08:00:00,10:00:00,same_day,prior_day",
+		"trip_id,3,,group,,,,09:00:00,11:00:00,,",
+		"trip_id,4,stop_2,,,10:00:00,10:00:00,,,,",
+		"trip_id,5,,unknown,,,,09:00:00,11:00:00,,",
+		"trip_id,6,,,zone,,,09:00:00,11:00:00,unknown,",
+		"trip_id,7,stop_1,group,,,,09:00:00,11:00:00,,",
+		"trip_id,8,,,,,,09:00:00,11:00:00,,",
+	).build()
+
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	var stopTimeWarnings []warnings.StaticWarningKind
+	for _, warning := range static.Warnings {
+		if warning.File == "stop_times.txt" {
+			stopTimeWarnings = append(stopTimeWarnings, warning.Kind)
+		}
+	}
+	if diff := cmp.Diff(stopTimeWarnings, []warnings.StaticWarningKind{
+		warnings.InvalidReference{Column: "location_group_id", Value: "unknown"},
+		warnings.InvalidReference{Column: "pickup_booking_rule_id", Value: "unknown"},
+		warnings.ConflictingValues{Columns: []string{"stop_id", "location_group_id", "location_id"}},
+		warnings.MissingOneOfValues{Columns: []string{"stop_id", "location_group_id", "location_id"}},
+	}); diff != "" {
+		t.Errorf("stop time warnings not the same: %s", diff)
+	}
+
+	zone := Location{
+		Id:          "zone",
+		Name:        "Downtown",
+		Description: "Downtown zone",
+		Polygons: [][][][2]float64{
+			{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+			},
+		},
+	}
+	multi := Location{
+		Id: "multi",
+		Polygons: [][][][2]float64{
+			{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+		},
+	}
+	if diff := cmp.Diff(static.Locations, []Location{zone, multi}); diff != "" {
+		t.Errorf("locations not the same: %s", diff)
+	}
+
+	group := LocationGroup{
+		Id:    "group",
+		Name:  "Group",
+		Stops: []*Stop{&static.Stops[0], &static.Stops[1]},
+	}
+	if diff := cmp.Diff(static.LocationGroups, []LocationGroup{group}); diff != "" {
+		t.Errorf("location groups not the same: %s", diff)
+	}
+
+	sameDay := BookingRule{
+		Id:                     "same_day",
+		Type:                   BookingType_SameDay,
+		PriorNoticeDurationMin: ptr(30 * time.Minute),
+		PriorNoticeDurationMax: ptr(2 * time.Hour),
+		Message:                "Call ahead",
+		PhoneNumber:            "555-0100",
+	}
+	priorDay := BookingRule{
+		Id:                  "prior_day",
+		Type:                BookingType_PriorDays,
+		PriorNoticeLastDay:  ptr(int32(1)),
+		PriorNoticeLastTime: ptr(17 * time.Hour),
+		PriorNoticeService:  &static.Services[0],
+	}
+	if diff := cmp.Diff(static.BookingRules, []BookingRule{sameDay, priorDay}); diff != "" {
+		t.Errorf("booking rules not the same: %s", diff)
+	}
+
+	for i, stopTime := range static.Trips[0].StopTimes {
+		if stopTime.Trip != &static.Trips[0] {
+			t.Errorf("stop time %d has trip %v, want %v", i, stopTime.Trip, &static.Trips[0])
+		}
+	}
+	if diff := cmp.Diff(static.Trips[0].StopTimes, []ScheduledStopTime{
+		{
+			Stop:              &static.Stops[0],
+			StopSequence:      1,
+			ArrivalTime:       8 * time.Hour,
+			DepartureTime:     8 * time.Hour,
+			HasArrivalTime:    true,
+			HasDepartureTime:  true,
+			ExactTimes:        true,
+			PickupType:        PickupDropOffPolicy_No,
+			DropOffType:       PickupDropOffPolicy_No,
+			ContinuousPickup:  PickupDropOffPolicy_No,
+			ContinuousDropOff: PickupDropOffPolicy_No,
+		},
+		{
+			Location:                 &zone,
+			StopSequence:             2,
+			ExactTimes:               true,
+			PickupType:               PickupDropOffPolicy_No,
+			DropOffType:              PickupDropOffPolicy_No,
+			ContinuousPickup:         PickupDropOffPolicy_No,
+			ContinuousDropOff:        PickupDropOffPolicy_No,
+			StartPickupDropOffWindow: ptr(8 * time.Hour),
+			EndPickupDropOffWindow:   ptr(10 * time.Hour),
+			PickupBookingRule:        &sameDay,
+			DropOffBookingRule:       &priorDay,
+		},
+		{
+			LocationGroup:            &group,
+			StopSequence:             3,
+			ExactTimes:               true,
+			PickupType:               PickupDropOffPolicy_No,
+			DropOffType:              PickupDropOffPolicy_No,
+			ContinuousPickup:         PickupDropOffPolicy_No,
+			ContinuousDropOff:        PickupDropOffPolicy_No,
+			StartPickupDropOffWindow: ptr(9 * time.Hour),
+			EndPickupDropOffWindow:   ptr(11 * time.Hour),
+		},
+		{
+			Stop:              &static.Stops[1],
+			StopSequence:      4,
+			ArrivalTime:       10 * time.Hour,
+			DepartureTime:     10 * time.Hour,
+			HasArrivalTime:    true,
+			HasDepartureTime:  true,
+			ExactTimes:        true,
+			PickupType:        PickupDropOffPolicy_No,
+			DropOffType:       PickupDropOffPolicy_No,
+			ContinuousPickup:  PickupDropOffPolicy_No,
+			ContinuousDropOff: PickupDropOffPolicy_No,
+		},
+	}, cmpopts.IgnoreFields(ScheduledStopTime{}, "Trip")); diff != "" {
+		t.Errorf("stop times not the same: %s", diff)
+	}
+}
+
+func TestParseFlex_MalformedLocations(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"locations.geojson",
+		`{"type": "FeatureCollection", "features": [`,
+	).build()
+
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	if len(static.Locations) != 0 {
+		t.Errorf("got locations %v, want none", static.Locations)
+	}
+	if len(static.Warnings) != 1 || static.Warnings[0].File != "locations.geojson" || static.Warnings[0].Kind.Code() != "invalid_file" {
+		t.Fatalf("got warnings %v, want a single invalid_file warning for locations.geojson", static.Warnings)
+	}
+	if severity := static.Warnings[0].Kind.Severity(); severity != warnings.Severity_Fatal {
+		t.Errorf("got severity %s, want %s", severity, warnings.Severity_Fatal)
+	}
+
+	_, err = ParseStatic(content, ParseStaticOptions{Strict: true})
+	var strictErr *StrictError
+	if !errors.As(err, &strictErr) {
+		t.Errorf("got error %v, want a *StrictError", err)
+	}
+}
+
+func TestLocationContains(t *testing.T) {
+	zone := Location{
+		Polygons: [][][][2]float64{
+			{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+			},
+			{
+				{{20, 20}, {30, 20}, {30, 30}, {20, 20}},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		latitude  float64
+		longitude float64
+		expected  bool
+	}{
+		{latitude: 1, longitude: 1, expected: true},
+		{latitude: 5, longitude: 5, expected: false},
+		{latitude: 21, longitude: 25, expected: true},
+		{latitude: 29, longitude: 21, expected: false},
+		{latitude: -1, longitude: 1, expected: false},
+	} {
+		if actual := zone.Contains(tc.latitude, tc.longitude); actual != tc.expected {
+			t.Errorf("Contains(%v, %v) = %t, want %t", tc.latitude, tc.longitude, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package gtfs
+
+// StaticIDs maps the IDs of the entities in a feed to the entities.
+//
+// The maps are built while the feed is parsed, are used by the parsers to resolve references and are kept in
+// Static.IDs for the ByID methods. Each map is nil if the file of the entities was not parsed.
+type StaticIDs struct {
+	agencies        map[string]*Agency
+	routes          map[string]*Route
+	levels          map[string]*Level
+	stops           map[string]*Stop
+	services        map[string]*Service
+	trips           map[string]*ScheduledTrip
+	shapes          map[string]*Shape
+	locationGroups  map[string]*LocationGroup
+	locations       map[string]*Location
+	bookingRules    map[string]*BookingRule
+	fareAttributes  map[string]*FareAttribute
+	riderCategories map[string]*RiderCategory
+	fareMedia       map[string]*FareMedia
+	fareProducts    map[string]*FareProduct
+	areas           map[string]*Area
+	timeframeGroups map[string][]*Timeframe
+}
+
+// Equal reports whether two StaticIDs are equal, which they always are as the maps are derived from the other
+// fields of the Static. This lets Static values be compared with go-cmp.
+func (ids *StaticIDs) Equal(other *StaticIDs) bool {
+	return true
+}
+
+func newStaticIDs(s *Static) *StaticIDs {
+	return &StaticIDs{
+		agencies:        indexByID(s.Agencies, (*Agency).id),
+		routes:          indexByID(s.Routes, (*Route).id),
+		levels:          indexByID(s.Levels, (*Level).id),
+		stops:           indexByID(s.Stops, (*Stop).id),
+		services:        indexByID(s.Services, (*Service).id),
+		trips:           indexByID(s.Trips, (*ScheduledTrip).id),
+		shapes:          indexByID(s.Shapes, (*Shape).id),
+		locationGroups:  indexByID(s.LocationGroups, (*LocationGroup).id),
+		locations:       indexByID(s.Locations, (*Location).id),
+		bookingRules:    indexByID(s.BookingRules, (*BookingRule).id),
+		fareAttributes:  indexByID(s.FareAttributes, (*FareAttribute).id),
+		riderCategories: indexByID(s.RiderCategories, (*RiderCategory).id),
+		fareMedia:       indexByID(s.FareMedia, (*FareMedia).id),
+		fareProducts:    indexByID(s.FareProducts, (*FareProduct).id),
+		areas:           indexByID(s.Areas, (*Area).id),
+		timeframeGroups: groupTimeframes(s.Timeframes),
+	}
+}
+
+func (agency *Agency) id() string          { return agency.Id }
+func (route *Route) id() string            { return route.Id }
+func (level *Level) id() string            { return level.Id }
+func (stop *Stop) id() string              { return stop.Id }
+func (service *Service) id() string        { return service.Id }
+func (trip *ScheduledTrip) id() string     { return trip.ID }
+func (shape *Shape) id() string            { return shape.ID }
+func (group *LocationGroup) id() string    { return group.Id }
+func (location *Location) id() string      { return location.Id }
+func (rule *BookingRule) id() string       { return rule.Id }
+func (fare *FareAttribute) id() string     { return fare.Id }
+func (category *RiderCategory) id() string { return category.Id }
+func (media *FareMedia) id() string        { return media.Id }
+func (product *FareProduct) id() string    { return product.Id }
+func (area *Area) id() string              { return area.Id }
+
+// indexByID returns a map from the ID of each item to the item.
+// If IDs are duplicated, the last item with the ID is used.
+func indexByID[T any](items []T, id func(*T) string) map[string]*T {
+	m := make(map[string]*T, len(items))
+	for i := range items {
+		m[id(&items[i])] = &items[i]
+	}
+	return m
+}
+
+// groupTimeframes returns a map from each timeframe group ID to the timeframes in the group.
+func groupTimeframes(timeframes []Timeframe) map[string][]*Timeframe {
+	m := map[string][]*Timeframe{}
+	for i := range timeframes {
+		m[timeframes[i].GroupId] = append(m[timeframes[i].GroupId], &timeframes[i])
+	}
+	return m
+}
+
+// lookupByID returns the item with the given ID, using the map if it was built and otherwise scanning the items.
+func lookupByID[T any](m map[string]*T, items []T, id func(*T) string, target string) (*T, bool) {
+	if m != nil {
+		item, ok := m[target]
+		return item, ok
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if id(&items[i]) == target {
+			return &items[i], true
+		}
+	}
+	return nil, false
+}
+
+// lookupAgency returns the agency with the provided ID.
+//
+// In GTFS static if there is a single agency, the agency ID field of other entities can be omitted in
+// which case the agency is the unique agency in the feed.
+func (ids *StaticIDs) lookupAgency(agencyID string) (*Agency, bool) {
+	if agencyID == "" {
+		if len(ids.agencies) == 1 {
+			for _, agency := range ids.agencies {
+				return agency, true
+			}
+		}
+		return nil, false
+	}
+	agency, ok := ids.agencies[agencyID]
+	return agency, ok
+}
+
+// ids returns the maps built when the feed was parsed, or empty maps if it was not parsed.
+func (s *Static) ids() *StaticIDs {
+	if s.IDs == nil {
+		return &StaticIDs{}
+	}
+	return s.IDs
+}
+
+// AgencyByID returns the agency with the given ID.
+//
+// The lookups by ID use maps built when the feed is parsed, and so take constant time.
+// If the slices of the Static are modified afterwards the results of the lookups are undefined.
+func (s *Static) AgencyByID(id string) (*Agency, bool) {
+	return lookupByID(s.ids().agencies, s.Agencies, (*Agency).id, id)
+}
+
+// RouteByID returns the route with the given ID. See AgencyByID for details.
+func (s *Static) RouteByID(id string) (*Route, bool) {
+	return lookupByID(s.ids().routes, s.Routes, (*Route).id, id)
+}
+
+// StopByID returns the stop with the given ID. See AgencyByID for details.
+func (s *Static) StopByID(id string) (*Stop, bool) {
+	return lookupByID(s.ids().stops, s.Stops, (*Stop).id, id)
+}
+
+// ServiceByID returns the service with the given ID. See AgencyByID for details.
+func (s *Static) ServiceByID(id string) (*Service, bool) {
+	return lookupByID(s.ids().services, s.Services, (*Service).id, id)
+}
+
+// TripByID returns the trip with the given ID. See AgencyByID for details.
+func (s *Static) TripByID(id string) (*ScheduledTrip, bool) {
+	return lookupByID(s.ids().trips, s.Trips, (*ScheduledTrip).id, id)
+}
+
+// ShapeByID returns the shape with the given ID. See AgencyByID for details.
+func (s *Static) ShapeByID(id string) (*Shape, bool) {
+	return lookupByID(s.ids().shapes, s.Shapes, (*Shape).id, id)
+}
@@ -0,0 +1,104 @@
+package gtfs
+
+import "testing"
+
+func TestStaticLookupByID(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id,stop_lat,stop_lon",
+		"stop_1,1,1",
+		"stop_2,50,50",
+	).add(
+		"shapes.txt",
+		"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+		"shape_1,0,0,1",
+		"shape_1,1,1,2",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id,shape_id",
+		"route_id,service_id,trip_1,shape_1",
+		"route_id,service_id,trip_2,",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_1,stop_1,1,08:00:00,08:00:00",
+		"trip_2,stop_2,1,09:00:00,09:00:00",
+	).build()
+
+	for _, tc := range []struct {
+		desc        string
+		static      func() *Static
+		wantTrip    string
+		missingTrip string
+	}{
+		{
+			desc: "parsed",
+			static: func() *Static {
+				static, err := ParseStatic(content, ParseStaticOptions{})
+				if err != nil {
+					t.Fatalf("error when parsing: %s", err)
+				}
+				return static
+			},
+			wantTrip:    "trip_2",
+			missingTrip: "trip_3",
+		},
+		{
+			desc: "parsed with filter",
+			static: func() *Static {
+				static, err := ParseStatic(content, ParseStaticOptions{
+					Filter: StaticFilter{
+						BoundingBox: &BoundingBox{MinLatitude: 0, MinLongitude: 0, MaxLatitude: 2, MaxLongitude: 2},
+					},
+				})
+				if err != nil {
+					t.Fatalf("error when parsing: %s", err)
+				}
+				return static
+			},
+			wantTrip:    "trip_1",
+			missingTrip: "trip_2",
+		},
+		{
+			desc: "not parsed",
+			static: func() *Static {
+				return &Static{
+					Agencies: []Agency{{Id: "a"}},
+					Routes:   []Route{{Id: "route_id"}},
+					Stops:    []Stop{{Id: "stop_1"}},
+					Services: []Service{{Id: "service_id"}},
+					Trips:    []ScheduledTrip{{ID: "trip_1"}, {ID: "trip_2"}},
+					Shapes:   []Shape{{ID: "shape_1"}},
+				}
+			},
+			wantTrip:    "trip_2",
+			missingTrip: "trip_3",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			static := tc.static()
+			if agency, ok := static.AgencyByID("a"); !ok || agency != &static.Agencies[0] {
+				t.Errorf("AgencyByID(%q) = %v, %t, want %v", "a", agency, ok, &static.Agencies[0])
+			}
+			if route, ok := static.RouteByID("route_id"); !ok || route != &static.Routes[0] {
+				t.Errorf("RouteByID(%q) = %v, %t, want %v", "route_id", route, ok, &static.Routes[0])
+			}
+			if stop, ok := static.StopByID("stop_1"); !ok || stop != &static.Stops[0] {
+				t.Errorf("StopByID(%q) = %v, %t, want %v", "stop_1", stop, ok, &static.Stops[0])
+			}
+			if service, ok := static.ServiceByID("service_id"); !ok || service != &static.Services[0] {
+				t.Errorf("ServiceByID(%q) = %v, %t, want %v", "service_id", service, ok, &static.Services[0])
+			}
+			if shape, ok := static.ShapeByID("shape_1"); !ok || shape != &static.Shapes[0] {
+				t.Errorf("ShapeByID(%q) = %v, %t, want %v", "shape_1", shape, ok, &static.Shapes[0])
+			}
+			wantTrip := &static.Trips[len(static.Trips)-1]
+			if trip, ok := static.TripByID(tc.wantTrip); !ok || trip != wantTrip {
+				t.Errorf("TripByID(%q) = %v, %t, want %v", tc.wantTrip, trip, ok, wantTrip)
+			}
+			if trip, ok := static.TripByID(tc.missingTrip); ok {
+				t.Errorf("TripByID(%q) = %v, want not found", tc.missingTrip, trip)
+			}
+		})
+	}
+}
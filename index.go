@@ -0,0 +1,57 @@
+package gtfs
+
+// StaticIndex contains the references between entities in a GTFS static feed that go in the opposite direction
+// to the pointers in the parsed types; for example, from a route to its trips.
+//
+// The index points into the Static it was built from and is not updated if the Static is modified.
+type StaticIndex struct {
+	// Trips of each route, in the order of Static.Trips.
+	TripsByRoute map[*Route][]*ScheduledTrip
+	// Trips of each service, in the order of Static.Trips.
+	TripsByService map[*Service][]*ScheduledTrip
+	// Trips of each shape, in the order of Static.Trips.
+	TripsByShape map[*Shape][]*ScheduledTrip
+	// Stop times at each stop, in the order of Static.Trips and then of the stop times of each trip.
+	// GTFS-Flex stop times at locations or location groups are not included.
+	StopTimesByStop map[*Stop][]*ScheduledStopTime
+	// Child stops of each stop, in the order of Static.Stops.
+	ChildStops map[*Stop][]*Stop
+}
+
+// Index builds the reverse references between the entities in the feed.
+//
+// If the stop times were passed to ParseStaticOptions.VisitStopTimes, StopTimesByStop is empty.
+func (s *Static) Index() *StaticIndex {
+	index := &StaticIndex{
+		TripsByRoute:    map[*Route][]*ScheduledTrip{},
+		TripsByService:  map[*Service][]*ScheduledTrip{},
+		TripsByShape:    map[*Shape][]*ScheduledTrip{},
+		StopTimesByStop: map[*Stop][]*ScheduledStopTime{},
+		ChildStops:      map[*Stop][]*Stop{},
+	}
+	for i := range s.Trips {
+		trip := &s.Trips[i]
+		if trip.Route != nil {
+			index.TripsByRoute[trip.Route] = append(index.TripsByRoute[trip.Route], trip)
+		}
+		if trip.Service != nil {
+			index.TripsByService[trip.Service] = append(index.TripsByService[trip.Service], trip)
+		}
+		if trip.Shape != nil {
+			index.TripsByShape[trip.Shape] = append(index.TripsByShape[trip.Shape], trip)
+		}
+		for j := range trip.StopTimes {
+			stopTime := &trip.StopTimes[j]
+			if stopTime.Stop != nil {
+				index.StopTimesByStop[stopTime.Stop] = append(index.StopTimesByStop[stopTime.Stop], stopTime)
+			}
+		}
+	}
+	for i := range s.Stops {
+		stop := &s.Stops[i]
+		if stop.Parent != nil {
+			index.ChildStops[stop.Parent] = append(index.ChildStops[stop.Parent], stop)
+		}
+	}
+	return index
+}
@@ -0,0 +1,88 @@
+package gtfs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStaticIndex(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"routes.txt",
+		"route_id,route_type",
+		"route_1,3",
+		"route_2,3",
+	).add(
+		"stops.txt",
+		"stop_id,location_type,parent_station",
+		"station,1,",
+		"stop_1,0,station",
+		"stop_2,0,station",
+		"stop_3,0,",
+	).add(
+		"shapes.txt",
+		"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+		"shape_1,0,0,1",
+		"shape_1,1,1,2",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id,shape_id",
+		"route_1,service_id,trip_1,shape_1",
+		"route_1,service_id,trip_2,",
+		"route_2,service_id,trip_3,shape_1",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_1,stop_1,1,08:00:00,08:00:00",
+		"trip_1,stop_3,2,08:10:00,08:10:00",
+		"trip_2,stop_3,1,09:00:00,09:00:00",
+		"trip_3,stop_2,1,10:00:00,10:00:00",
+	).build()
+
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	index := static.Index()
+
+	tripIDs := func(trips []*ScheduledTrip) []string {
+		var ids []string
+		for _, trip := range trips {
+			ids = append(ids, trip.ID)
+		}
+		return ids
+	}
+	stopIDs := func(stops []*Stop) []string {
+		var ids []string
+		for _, stop := range stops {
+			ids = append(ids, stop.Id)
+		}
+		return ids
+	}
+	stopTimeTripIDs := func(stopTimes []*ScheduledStopTime) []string {
+		var ids []string
+		for _, stopTime := range stopTimes {
+			ids = append(ids, stopTime.Trip.ID)
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		desc string
+		got  []string
+		want []string
+	}{
+		{"trips of route_1", tripIDs(index.TripsByRoute[&static.Routes[0]]), []string{"trip_1", "trip_2"}},
+		{"trips of route_2", tripIDs(index.TripsByRoute[&static.Routes[1]]), []string{"trip_3"}},
+		{"trips of service", tripIDs(index.TripsByService[&static.Services[0]]), []string{"trip_1", "trip_2", "trip_3"}},
+		{"trips of shape", tripIDs(index.TripsByShape[&static.Shapes[0]]), []string{"trip_1", "trip_3"}},
+		{"child stops of station", stopIDs(index.ChildStops[&static.Stops[0]]), []string{"stop_1", "stop_2"}},
+		{"child stops of stop", stopIDs(index.ChildStops[&static.Stops[1]]), nil},
+		{"stop times at stop_1", stopTimeTripIDs(index.StopTimesByStop[&static.Stops[1]]), []string{"trip_1"}},
+		{"stop times at stop_3", stopTimeTripIDs(index.StopTimesByStop[&static.Stops[3]]), []string{"trip_1", "trip_2"}},
+	} {
+		if diff := cmp.Diff(tc.want, tc.got); diff != "" {
+			t.Errorf("%s not the same: %s", tc.desc, diff)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package gtfs
 
 import (
+	"math"
 	"time"
 )
 
@@ -20,7 +21,7 @@ func interpolateStopTimes(times []ScheduledStopTime) []ScheduledStopTime {
 		i := 0
 		for i < n {
 			// Find the next known time
-			if getTime(&result[i], tType) == 0 {
+			if !hasTime(&result[i], tType) {
 				// Start of missing segment
 				startIdx := i - 1
 				startTime := time.Duration(0)
@@ -29,7 +30,7 @@ func interpolateStopTimes(times []ScheduledStopTime) []ScheduledStopTime {
 				}
 				// Find end of missing segment
 				endIdx := i
-				for endIdx < n && getTime(&result[endIdx], tType) == 0 {
+				for endIdx < n && !hasTime(&result[endIdx], tType) {
 					endIdx++
 				}
 				endTime := time.Duration(0)
@@ -53,6 +54,17 @@ func interpolateStopTimes(times []ScheduledStopTime) []ScheduledStopTime {
 }
 
 func interpolateStopTimesByShapeDist(times []ScheduledStopTime) []ScheduledStopTime {
+	distances := make([]*float64, len(times))
+	for i := range times {
+		distances[i] = times[i].ShapeDistanceTraveled
+	}
+	return interpolateStopTimesByDistance(times, distances)
+}
+
+// interpolateStopTimesByDistance interpolates missing times in proportion to the distance travelled.
+//
+// The distances slice contains the distance travelled at each stop time, or nil if it is unknown.
+func interpolateStopTimesByDistance(times []ScheduledStopTime, distances []*float64) []ScheduledStopTime {
 	result := make([]ScheduledStopTime, len(times))
 	copy(result, times)
 	n := len(result)
@@ -63,28 +75,35 @@ func interpolateStopTimesByShapeDist(times []ScheduledStopTime) []ScheduledStopT
 	for tType := 0; tType < 2; tType++ {
 		i := 0
 		for i < n {
-			if getTime(&result[i], tType) == 0 {
+			if !hasTime(&result[i], tType) {
 				startIdx := i - 1
 				var startTime time.Duration
 				var startDist float64
-				if startIdx >= 0 && result[startIdx].ShapeDistanceTraveled != nil {
+				if startIdx >= 0 && distances[startIdx] != nil {
 					startTime = getTime(&result[startIdx], tType)
-					startDist = *result[startIdx].ShapeDistanceTraveled
+					startDist = *distances[startIdx]
 				}
 				endIdx := i
-				for endIdx < n && getTime(&result[endIdx], tType) == 0 {
+				for endIdx < n && !hasTime(&result[endIdx], tType) {
 					endIdx++
 				}
 				var endTime time.Duration
 				var endDist float64
-				if endIdx < n && result[endIdx].ShapeDistanceTraveled != nil {
+				if endIdx < n && distances[endIdx] != nil {
 					endTime = getTime(&result[endIdx], tType)
-					endDist = *result[endIdx].ShapeDistanceTraveled
+					endDist = *distances[endIdx]
 				}
 				intervals := endIdx - startIdx
-				if startIdx >= 0 && endIdx < n && endDist > startDist && endTime > startTime && intervals > 0 {
+				// Every stop time in the segment needs a distance; otherwise the segment is left as is.
+				hasDistances := true
+				for j := 1; j < intervals; j++ {
+					if distances[startIdx+j] == nil {
+						hasDistances = false
+					}
+				}
+				if startIdx >= 0 && endIdx < n && hasDistances && endDist > startDist && endTime > startTime && intervals > 0 {
 					for j := 1; j < intervals; j++ {
-						dist := *result[startIdx+j].ShapeDistanceTraveled
+						dist := *distances[startIdx+j]
 						w := (dist - startDist) / (endDist - startDist)
 						interpolated := startTime + time.Duration(float64(endTime-startTime)*w)
 						setTime(&result[startIdx+j], tType, interpolated)
@@ -99,7 +118,132 @@ func interpolateStopTimesByShapeDist(times []ScheduledStopTime) []ScheduledStopT
 	return result
 }
 
+// interpolateStopTimesByGeometry interpolates missing times in proportion to the distance along the
+// shape of the trip, or to the straight-line distance between stops if the trip has no shape.
+//
+// If a stop time has no coordinates, for example a GTFS-Flex stop time, the times are spaced evenly instead.
+func interpolateStopTimesByGeometry(times []ScheduledStopTime, shape *Shape) []ScheduledStopTime {
+	coordinates := make([]latLon, len(times))
+	for i := range times {
+		c, ok := stopCoordinates(times[i].Stop)
+		if !ok {
+			return interpolateStopTimes(times)
+		}
+		coordinates[i] = c
+	}
+	var distances []*float64
+	if shape != nil && len(shape.Points) >= 2 {
+		distances = distancesAlongShape(coordinates, shape.Points)
+	} else {
+		distances = straightLineDistances(coordinates)
+	}
+	return interpolateStopTimesByDistance(times, distances)
+}
+
+type latLon struct {
+	lat float64
+	lon float64
+}
+
+// stopCoordinates returns the coordinates of the stop, using the coordinates of the parent station if
+// the stop has none.
+func stopCoordinates(stop *Stop) (latLon, bool) {
+	for ; stop != nil; stop = stop.Parent {
+		if stop.Latitude != nil && stop.Longitude != nil {
+			return latLon{lat: *stop.Latitude, lon: *stop.Longitude}, true
+		}
+	}
+	return latLon{}, false
+}
+
+// straightLineDistances returns the cumulative straight-line distance in meters at each point.
+func straightLineDistances(coordinates []latLon) []*float64 {
+	distances := make([]*float64, len(coordinates))
+	var d float64
+	for i := range coordinates {
+		if i > 0 {
+			d += haversineDistance(coordinates[i-1], coordinates[i])
+		}
+		d := d
+		distances[i] = &d
+	}
+	return distances
+}
+
+// distancesAlongShape returns the distance in meters along the shape at which each point is closest
+// to the shape.
+//
+// Each point is matched to the closest segment of the shape that is not before the segment matched to the
+// previous point, so that shapes passing the same place twice are handled.
+func distancesAlongShape(coordinates []latLon, points []ShapePoint) []*float64 {
+	// Distance along the shape at the start of each segment.
+	segmentStart := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		segmentStart[i] = segmentStart[i-1] + haversineDistance(shapePointCoordinates(points[i-1]), shapePointCoordinates(points[i]))
+	}
+	distances := make([]*float64, len(coordinates))
+	segment := 0
+	var previous float64
+	for i, c := range coordinates {
+		bestSegment := segment
+		bestDistance := math.Inf(1)
+		var bestFraction float64
+		for k := segment; k < len(points)-1; k++ {
+			fraction, distance := projectOntoSegment(c, shapePointCoordinates(points[k]), shapePointCoordinates(points[k+1]))
+			if distance < bestDistance {
+				bestSegment, bestDistance, bestFraction = k, distance, fraction
+			}
+		}
+		segment = bestSegment
+		d := segmentStart[segment] + bestFraction*(segmentStart[segment+1]-segmentStart[segment])
+		if d < previous {
+			d = previous
+		}
+		previous = d
+		distances[i] = &d
+	}
+	return distances
+}
+
+func shapePointCoordinates(p ShapePoint) latLon {
+	return latLon{lat: p.Latitude, lon: p.Longitude}
+}
+
+const earthRadiusMeters = 6371000
+
+// haversineDistance returns the great-circle distance in meters between two points.
+func haversineDistance(a, b latLon) float64 {
+	lat1 := a.lat * math.Pi / 180
+	lat2 := b.lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.lon - a.lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// projectOntoSegment returns the fraction of the way from a to b of the point of the segment closest to p,
+// and the distance in meters from p to that point.
+//
+// It uses an equirectangular approximation, which is accurate for the short segments of shapes.
+func projectOntoSegment(p, a, b latLon) (float64, float64) {
+	cosLat := math.Cos(a.lat * math.Pi / 180)
+	bx, by := (b.lon-a.lon)*cosLat, b.lat-a.lat
+	px, py := (p.lon-a.lon)*cosLat, p.lat-a.lat
+	var fraction float64
+	if l := bx*bx + by*by; l > 0 {
+		fraction = math.Max(0, math.Min(1, (px*bx+py*by)/l))
+	}
+	dx, dy := px-fraction*bx, py-fraction*by
+	return fraction, math.Sqrt(dx*dx+dy*dy) * earthRadiusMeters * math.Pi / 180
+}
+
 // Helpers to get/set arrival/departure by index
+//
+// A time is known if either time was given, as a time that is not given is then copied from the other one.
+func hasTime(stop *ScheduledStopTime, tType int) bool {
+	return stop.HasArrivalTime || stop.HasDepartureTime
+}
+
 func getTime(stop *ScheduledStopTime, tType int) time.Duration {
 	if tType == 0 {
 		return stop.ArrivalTime
@@ -19,10 +19,10 @@ func almostEq(a, b time.Duration) bool {
 
 func TestInterpolateStopTimes_Normal(t *testing.T) {
 	st := []ScheduledStopTime{
-		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:05:00"), ExactTimes: true},
+		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:05:00"), HasArrivalTime: true, HasDepartureTime: true, ExactTimes: true},
 		{StopSequence: 2, ArrivalTime: 0, DepartureTime: 0},
 		{StopSequence: 3, ArrivalTime: 0, DepartureTime: 0},
-		{StopSequence: 4, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:35:00"), ExactTimes: true},
+		{StopSequence: 4, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:35:00"), HasArrivalTime: true, HasDepartureTime: true, ExactTimes: true},
 	}
 	wantArr := []time.Duration{dur("08:00:00"), dur("08:10:00"), dur("08:20:00"), dur("08:30:00")}
 	wantDep := []time.Duration{dur("08:05:00"), dur("08:15:00"), dur("08:25:00"), dur("08:35:00")}
@@ -90,10 +90,10 @@ func TestInterpolateStopTimes_MissingLast(t *testing.T) {
 
 func TestInterpolateStopTimesByShapeDist_Normal(t *testing.T) {
 	st := []ScheduledStopTime{
-		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:05:00"), ShapeDistanceTraveled: ptr(0.0), ExactTimes: true},
+		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:05:00"), HasArrivalTime: true, HasDepartureTime: true, ShapeDistanceTraveled: ptr(0.0), ExactTimes: true},
 		{StopSequence: 2, ArrivalTime: 0, DepartureTime: 0, ShapeDistanceTraveled: ptr(3.5)},
 		{StopSequence: 3, ArrivalTime: 0, DepartureTime: 0, ShapeDistanceTraveled: ptr(7.0)},
-		{StopSequence: 4, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:35:00"), ShapeDistanceTraveled: ptr(10.5), ExactTimes: true},
+		{StopSequence: 4, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:35:00"), HasArrivalTime: true, HasDepartureTime: true, ShapeDistanceTraveled: ptr(10.5), ExactTimes: true},
 	}
 	wantArr := []time.Duration{dur("08:00:00"), dur("08:10:00"), dur("08:20:00"), dur("08:30:00")}
 	wantDep := []time.Duration{dur("08:05:00"), dur("08:15:00"), dur("08:25:00"), dur("08:35:00")}
@@ -117,7 +117,7 @@ func TestInterpolateStopTimesByShapeDist_MissingFirst(t *testing.T) {
 	st := []ScheduledStopTime{
 		{StopSequence: 1, ArrivalTime: 0, DepartureTime: 0, ShapeDistanceTraveled: ptr(0.0)},
 		{StopSequence: 2, ArrivalTime: 0, DepartureTime: 0, ShapeDistanceTraveled: ptr(3.5)},
-		{StopSequence: 3, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:35:00"), ShapeDistanceTraveled: ptr(10.5)},
+		{StopSequence: 3, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:35:00"), HasArrivalTime: true, HasDepartureTime: true, ShapeDistanceTraveled: ptr(10.5)},
 	}
 	got := interpolateStopTimesByShapeDist(st)
 	// The first two should remain zero, last should be as input
@@ -137,7 +137,7 @@ func TestInterpolateStopTimesByShapeDist_MissingFirst(t *testing.T) {
 
 func TestInterpolateStopTimesByShapeDist_MissingLast(t *testing.T) {
 	st := []ScheduledStopTime{
-		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:05:00"), ShapeDistanceTraveled: ptr(0.0)},
+		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:05:00"), HasArrivalTime: true, HasDepartureTime: true, ShapeDistanceTraveled: ptr(0.0)},
 		{StopSequence: 2, ArrivalTime: 0, DepartureTime: 0, ShapeDistanceTraveled: ptr(3.5)},
 		{StopSequence: 3, ArrivalTime: 0, DepartureTime: 0, ShapeDistanceTraveled: ptr(10.5)},
 	}
@@ -156,3 +156,95 @@ func TestInterpolateStopTimesByShapeDist_MissingLast(t *testing.T) {
 		t.Errorf("shape last missing: departures should remain zero, got: %v %v", got[1].DepartureTime, got[2].DepartureTime)
 	}
 }
+
+func TestInterpolateStopTimesByShapeDist_MissingDistance(t *testing.T) {
+	st := []ScheduledStopTime{
+		{StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:00:00"), HasArrivalTime: true, HasDepartureTime: true, ShapeDistanceTraveled: ptr(0.0)},
+		{StopSequence: 2, ArrivalTime: 0, DepartureTime: 0},
+		{StopSequence: 3, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:30:00"), HasArrivalTime: true, HasDepartureTime: true, ShapeDistanceTraveled: ptr(10.5)},
+	}
+	got := interpolateStopTimesByShapeDist(st)
+	if got[1].ArrivalTime != 0 || got[1].DepartureTime != 0 {
+		t.Errorf("shape missing distance: times should remain zero, got: %v %v", got[1].ArrivalTime, got[1].DepartureTime)
+	}
+}
+
+func TestInterpolateStopTimes_Midnight(t *testing.T) {
+	st := []ScheduledStopTime{
+		{StopSequence: 1, ArrivalTime: 0, DepartureTime: 0, HasArrivalTime: true, HasDepartureTime: true},
+		{StopSequence: 2, ArrivalTime: 0, DepartureTime: 0},
+		{StopSequence: 3, ArrivalTime: dur("00:10:00"), DepartureTime: dur("00:10:00"), HasArrivalTime: true, HasDepartureTime: true},
+	}
+	got := interpolateStopTimes(st)
+	if !almostEq(got[1].ArrivalTime, dur("00:05:00")) {
+		t.Errorf("midnight: arrival wrong, got %v", got[1].ArrivalTime)
+	}
+	if !almostEq(got[1].DepartureTime, dur("00:05:00")) {
+		t.Errorf("midnight: depart wrong, got %v", got[1].DepartureTime)
+	}
+	if got[1].HasArrivalTime || got[1].HasDepartureTime {
+		t.Errorf("midnight: interpolated times should not be marked as given")
+	}
+}
+
+// --- Tests for interpolateStopTimesByGeometry ---
+
+func TestInterpolateStopTimesByGeometry(t *testing.T) {
+	stopAt := func(lat, lon float64) *Stop {
+		return &Stop{Latitude: ptr(lat), Longitude: ptr(lon)}
+	}
+	// The shape goes north, then east, then south. The second stop is at the first corner.
+	shape := &Shape{
+		Points: []ShapePoint{
+			{Latitude: 0, Longitude: 0},
+			{Latitude: 0.01, Longitude: 0},
+			{Latitude: 0.01, Longitude: 0.01},
+			{Latitude: 0, Longitude: 0.01},
+		},
+	}
+	for _, tc := range []struct {
+		name  string
+		stops []*Stop
+		shape *Shape
+		want  time.Duration
+	}{
+		{
+			name:  "along shape",
+			stops: []*Stop{stopAt(0, 0), stopAt(0.01, 0), stopAt(0, 0.01)},
+			shape: shape,
+			want:  dur("08:10:00"),
+		},
+		{
+			name:  "straight line",
+			stops: []*Stop{stopAt(0, 0), stopAt(0.01, 0), stopAt(0, 0.01)},
+			want:  dur("08:12:26"),
+		},
+		{
+			name:  "stop without coordinates",
+			stops: []*Stop{stopAt(0, 0), {}, stopAt(0, 0.01)},
+			shape: shape,
+			want:  dur("08:15:00"),
+		},
+		{
+			name:  "coordinates of parent",
+			stops: []*Stop{stopAt(0, 0), {Parent: stopAt(0.01, 0)}, stopAt(0, 0.01)},
+			shape: shape,
+			want:  dur("08:10:00"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := []ScheduledStopTime{
+				{Stop: tc.stops[0], StopSequence: 1, ArrivalTime: dur("08:00:00"), DepartureTime: dur("08:00:00"), HasArrivalTime: true, HasDepartureTime: true},
+				{Stop: tc.stops[1], StopSequence: 2},
+				{Stop: tc.stops[2], StopSequence: 3, ArrivalTime: dur("08:30:00"), DepartureTime: dur("08:30:00"), HasArrivalTime: true, HasDepartureTime: true},
+			}
+			got := interpolateStopTimesByGeometry(st, tc.shape)
+			if !almostEq(got[1].ArrivalTime, tc.want) {
+				t.Errorf("arrival: want %v got %v", tc.want, got[1].ArrivalTime)
+			}
+			if !almostEq(got[1].DepartureTime, tc.want) {
+				t.Errorf("depart: want %v got %v", tc.want, got[1].DepartureTime)
+			}
+		})
+	}
+}
@@ -124,8 +124,7 @@ func BuildJournal(source GtfsrtSource, startTime, endTime time.Time) *Journal {
 		createdAt := feedMessage.CreatedAt
 		newActiveTrips := map[string]bool{}
 		for _, tripUpdate := range feedMessage.Trips {
-			startTime := tripUpdate.ID.StartDate.Add(tripUpdate.ID.StartTime)
-			tripUID := fmt.Sprintf("%d%s", startTime.Unix(), tripUpdate.ID.ID[6:])
+			tripUID := buildTripUID(&tripUpdate.ID)
 			if existingTrip, ok := trips[tripUID]; ok {
 				existingTrip.update(&tripUpdate, createdAt)
 			} else {
@@ -168,16 +167,28 @@ func BuildJournal(source GtfsrtSource, startTime, endTime time.Time) *Journal {
 	return j
 }
 
+// buildTripUID returns an identifier for the trip that is unique across days.
+//
+// The first 6 characters of the trip ID are replaced by the start time of the trip.
+// Trip IDs that are too short are used whole.
+func buildTripUID(tripID *gtfs.TripID) string {
+	startTime := tripID.StartDate.Add(tripID.StartTime)
+	suffix := tripID.ID
+	if len(suffix) >= 6 {
+		suffix = suffix[6:]
+	}
+	return fmt.Sprintf("%d%s", startTime.Unix(), suffix)
+}
+
 func (trip *Trip) update(tripUpdate *gtfs.Trip, feedCreatedAt time.Time) {
 	if trip.IsAssigned && tripUpdate.Vehicle == nil {
 		// TODO: this seems to happen a lot, would be nice to figure out what's happening.
 		// log.Printf("skipping unassigned update for assigned trip %s\n", trip.TripUID)
 		return
 	}
-	startTime := tripUpdate.ID.StartDate.Add(tripUpdate.ID.StartTime)
 	vehicle := tripUpdate.GetVehicle()
 
-	trip.TripUID = fmt.Sprintf("%d%s", startTime.Unix(), tripUpdate.ID.ID[6:])
+	trip.TripUID = buildTripUID(&tripUpdate.ID)
 	trip.TripID = tripUpdate.ID.ID
 	trip.RouteID = tripUpdate.ID.RouteID
 	trip.DirectionID = tripUpdate.ID.DirectionID
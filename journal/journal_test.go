@@ -190,3 +190,34 @@ func mtp(r int64) *time.Time {
 func ptr[T any](t T) *T {
 	return &t
 }
+
+func TestJournal_ShortTripID(t *testing.T) {
+	source := &testGtfsrtSource{
+		feeds: []*gtfs.Realtime{
+			{
+				CreatedAt: mt(0),
+				Trips: []gtfs.Trip{
+					{
+						ID: gtfs.TripID{
+							ID:        "L_1",
+							StartDate: time.Unix(0, 0).UTC(),
+							StartTime: 100 * time.Second,
+						},
+						Vehicle: &gtfs.Vehicle{
+							ID: &gtfs.VehicleID{ID: trainID1},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	j := BuildJournal(source, time.Unix(0, 0), time.Unix(10000, 0))
+
+	if len(j.Trips) != 1 {
+		t.Fatalf("got %d trips, want 1", len(j.Trips))
+	}
+	if got, want := j.Trips[0].TripUID, "100L_1"; got != want {
+		t.Errorf("TripUID = %q, want %q", got, want)
+	}
+}
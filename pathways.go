@@ -0,0 +1,311 @@
+package gtfs
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/csv"
+	"github.com/OneBusAway/go-gtfs/warnings"
+)
+
+// Level corresponds to a single row in the levels.txt file.
+type Level struct {
+	Id string
+	// Numeric index of the level that indicates its relative position.
+	// Ground level should have index 0, with levels above ground indicated by positive indices
+	// and levels below ground by negative indices.
+	Index float64
+	Name  string
+}
+
+// Pathway corresponds to a single row in the pathways.txt file.
+//
+// A pathway is an edge in the graph of locations within a station, linking entrances, generic nodes,
+// boarding areas and platforms.
+type Pathway struct {
+	Id   string
+	From *Stop
+	To   *Stop
+	Mode PathwayMode
+	// If false the pathway can only be used in the direction from From to To.
+	IsBidirectional bool
+	// Horizontal length in meters of the pathway.
+	Length *float64
+	// Average time needed to walk through the pathway.
+	TraversalTime *time.Duration
+	// Number of stairs of the pathway. Positive if the stairs go up from From to To, negative otherwise.
+	StairCount *int32
+	// Maximum slope ratio of the pathway.
+	MaxSlope *float64
+	// Minimum width of the pathway in meters.
+	MinWidth             *float64
+	SignpostedAs         string
+	ReversedSignpostedAs string
+}
+
+func parseLevels(csv *csv.File) ([]Level, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("level_id")
+	indexColumn := csv.RequiredColumn("level_index")
+	nameColumn := csv.OptionalColumn("level_name")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var levels []Level
+	for csv.NextRow() {
+		levelID := idColumn.Read()
+		rawIndex := indexColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		index := parseFloat64(rawIndex)
+		if index == nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "level_index", Value: rawIndex}))
+			continue
+		}
+		levels = append(levels, Level{
+			Id:    levelID,
+			Index: *index,
+			Name:  nameColumn.Read(),
+		})
+	}
+	return levels, w
+}
+
+func parsePathways(csv *csv.File, ids *StaticIDs) ([]Pathway, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	idColumn := csv.RequiredColumn("pathway_id")
+	fromStopIDColumn := csv.RequiredColumn("from_stop_id")
+	toStopIDColumn := csv.RequiredColumn("to_stop_id")
+	modeColumn := csv.RequiredColumn("pathway_mode")
+	isBidirectionalColumn := csv.RequiredColumn("is_bidirectional")
+	lengthColumn := csv.OptionalColumn("length")
+	traversalTimeColumn := csv.OptionalColumn("traversal_time")
+	stairCountColumn := csv.OptionalColumn("stair_count")
+	maxSlopeColumn := csv.OptionalColumn("max_slope")
+	minWidthColumn := csv.OptionalColumn("min_width")
+	signpostedAsColumn := csv.OptionalColumn("signposted_as")
+	reversedSignpostedAsColumn := csv.OptionalColumn("reversed_signposted_as")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var pathways []Pathway
+	for csv.NextRow() {
+		pathwayID := idColumn.Read()
+		fromStopID := fromStopIDColumn.Read()
+		toStopID := toStopIDColumn.Read()
+		rawMode := modeColumn.Read()
+		isBidirectional := isBidirectionalColumn.Read()
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		fromStop, ok := ids.stops[fromStopID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "from_stop_id", Value: fromStopID}))
+			continue
+		}
+		toStop, ok := ids.stops[toStopID]
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "to_stop_id", Value: toStopID}))
+			continue
+		}
+		mode, ok := parsePathwayMode(rawMode)
+		if !ok {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "pathway_mode", Value: rawMode}))
+			continue
+		}
+		if isBidirectional != "0" && isBidirectional != "1" {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "is_bidirectional", Value: isBidirectional}))
+			continue
+		}
+		pathway := Pathway{
+			Id:                   pathwayID,
+			From:                 fromStop,
+			To:                   toStop,
+			Mode:                 mode,
+			IsBidirectional:      isBidirectional == "1",
+			Length:               parseFloat64(lengthColumn.Read()),
+			StairCount:           parseInt32(stairCountColumn.Read()),
+			MaxSlope:             parseFloat64(maxSlopeColumn.Read()),
+			MinWidth:             parseFloat64(minWidthColumn.Read()),
+			SignpostedAs:         signpostedAsColumn.Read(),
+			ReversedSignpostedAs: reversedSignpostedAsColumn.Read(),
+		}
+		if traversalTime := parseInt32(traversalTimeColumn.Read()); traversalTime != nil {
+			d := time.Duration(*traversalTime) * time.Second
+			pathway.TraversalTime = &d
+		}
+		pathways = append(pathways, pathway)
+	}
+	return pathways, w
+}
+
+// PathwayRouteOptions contains options for finding routes through pathways.
+type PathwayRouteOptions struct {
+	// If true, the route does not use stairs, escalators or other pathways with a stair count, and does
+	// not pass through stops whose wheelchair boarding field (or that of their parent station, if
+	// unspecified) indicates that wheelchair boarding is not possible.
+	StepFree bool
+	// Walking speed in meters per second, used to estimate the traversal time of pathways that
+	// have a length but no traversal time. Defaults to 1.2.
+	WalkingSpeed float64
+}
+
+// PathwayRoute is a route between two stops through pathways.
+type PathwayRoute struct {
+	Steps []PathwayRouteStep
+	// Total time to traverse the route.
+	//
+	// For pathways without a traversal time, the time is estimated from the pathway's length. Pathways
+	// with neither a traversal time or a length do not contribute to the total time.
+	TraversalTime time.Duration
+}
+
+// PathwayRouteStep is a single pathway in a route.
+type PathwayRouteStep struct {
+	Pathway *Pathway
+	// If true, the pathway is traversed from its To stop to its From stop.
+	Reversed bool
+}
+
+// FindPathwayRoute finds the fastest route between the two stops through the feed's pathways.
+//
+// The boolean return value is false if there is no route between the stops.
+func (s *Static) FindPathwayRoute(from, to *Stop, opts PathwayRouteOptions) (*PathwayRoute, bool) {
+	if opts.WalkingSpeed <= 0 {
+		opts.WalkingSpeed = 1.2
+	}
+	if opts.StepFree && (!isStepFreeStop(from) || !isStepFreeStop(to)) {
+		return nil, false
+	}
+	type edge struct {
+		step PathwayRouteStep
+		to   *Stop
+		cost time.Duration
+	}
+	stopIDToEdges := map[string][]edge{}
+	for i := range s.Pathways {
+		pathway := &s.Pathways[i]
+		if opts.StepFree {
+			if pathway.Mode == PathwayMode_Stairs || pathway.Mode == PathwayMode_Escalator {
+				continue
+			}
+			if pathway.StairCount != nil && *pathway.StairCount != 0 {
+				continue
+			}
+			if !isStepFreeStop(pathway.From) || !isStepFreeStop(pathway.To) {
+				continue
+			}
+		}
+		cost := estimateTraversalTime(pathway, opts.WalkingSpeed)
+		stopIDToEdges[pathway.From.Id] = append(stopIDToEdges[pathway.From.Id], edge{
+			step: PathwayRouteStep{Pathway: pathway},
+			to:   pathway.To,
+			cost: cost,
+		})
+		if pathway.IsBidirectional {
+			stopIDToEdges[pathway.To.Id] = append(stopIDToEdges[pathway.To.Id], edge{
+				step: PathwayRouteStep{Pathway: pathway, Reversed: true},
+				to:   pathway.From,
+				cost: cost,
+			})
+		}
+	}
+
+	// Dijkstra's algorithm.
+	type visit struct {
+		previousStopID string
+		step           PathwayRouteStep
+		cost           time.Duration
+		done           bool
+	}
+	visits := map[string]*visit{from.Id: {}}
+	queue := &pathwayQueue{{stopID: from.Id}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathwayQueueItem)
+		current := visits[item.stopID]
+		if current.done {
+			continue
+		}
+		current.done = true
+		if item.stopID == to.Id {
+			break
+		}
+		for _, e := range stopIDToEdges[item.stopID] {
+			cost := current.cost + e.cost
+			next, ok := visits[e.to.Id]
+			if ok && (next.done || next.cost <= cost) {
+				continue
+			}
+			visits[e.to.Id] = &visit{
+				previousStopID: item.stopID,
+				step:           e.step,
+				cost:           cost,
+			}
+			heap.Push(queue, pathwayQueueItem{stopID: e.to.Id, cost: cost})
+		}
+	}
+
+	end, ok := visits[to.Id]
+	if !ok || !end.done {
+		return nil, false
+	}
+	route := &PathwayRoute{TraversalTime: end.cost}
+	for stopID := to.Id; stopID != from.Id; stopID = visits[stopID].previousStopID {
+		route.Steps = append(route.Steps, visits[stopID].step)
+	}
+	for i, j := 0, len(route.Steps)-1; i < j; i, j = i+1, j-1 {
+		route.Steps[i], route.Steps[j] = route.Steps[j], route.Steps[i]
+	}
+	return route, true
+}
+
+func estimateTraversalTime(pathway *Pathway, walkingSpeed float64) time.Duration {
+	if pathway.TraversalTime != nil {
+		return *pathway.TraversalTime
+	}
+	if pathway.Length != nil {
+		return time.Duration(*pathway.Length / walkingSpeed * float64(time.Second))
+	}
+	return 0
+}
+
+// isStepFreeStop returns false if wheelchair boarding is not possible at the stop.
+//
+// If the stop does not specify wheelchair boarding information it is inherited from the parent station.
+func isStepFreeStop(stop *Stop) bool {
+	for ; stop != nil; stop = stop.Parent {
+		switch stop.WheelchairBoarding {
+		case WheelchairBoarding_Possible:
+			return true
+		case WheelchairBoarding_NotPossible:
+			return false
+		}
+	}
+	return true
+}
+
+type pathwayQueueItem struct {
+	stopID string
+	cost   time.Duration
+}
+
+// pathwayQueue is a priority queue of stops ordered by cost, used in FindPathwayRoute.
+type pathwayQueue []pathwayQueueItem
+
+func (q pathwayQueue) Len() int           { return len(q) }
+func (q pathwayQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q pathwayQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathwayQueue) Push(x any)        { *q = append(*q, x.(pathwayQueueItem)) }
+func (q *pathwayQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
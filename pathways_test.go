@@ -0,0 +1,244 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+	"github.com/OneBusAway/go-gtfs/warnings"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePathways(t *testing.T) {
+	content := newZipBuilder().add(
+		"levels.txt",
+		"level_id,level_index,level_name",
+		"street,0,Street",
+		"mezzanine,-1,Mezzanine",
+		"platform,-2.5,",
+		"invalid,lower,",
+	).add(
+		"stops.txt",
+		"stop_id,location_type,parent_station,level_id",
+		"station,1,,",
+		"entrance,2,station,street",
+		"node,3,station,mezzanine",
+		"platform,0,station,platform",
+		"unknown_level,3,station,basement",
+	).add(
+		"pathways.txt",
+		"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,length,traversal_time,stair_count,max_slope,min_width,signposted_as,reversed_signposted_as",
+		"stairs,entrance,node,2,1,10.5,60,-20,,1.5,To platforms,To street",
+		"escalator,node,platform,4,0,,45,,,,,",
+		"unknown_stop,node,unknown,1,1,,,,,,,",
+		"unknown_mode,node,platform,8,1,,,,,,,",
+		"invalid_bidirectional,node,platform,1,yes,,,,,,,",
+	).build()
+
+	actual, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	street := Level{Id: "street", Index: 0, Name: "Street"}
+	mezzanine := Level{Id: "mezzanine", Index: -1, Name: "Mezzanine"}
+	platformLevel := Level{Id: "platform", Index: -2.5}
+	station := Stop{Id: "station", Type: StopType_Station}
+	entrance := Stop{Id: "entrance", Type: StopType_EntranceOrExit, Parent: &station, Level: &street}
+	node := Stop{Id: "node", Type: StopType_GenericNode, Parent: &station, Level: &mezzanine}
+	platform := Stop{Id: "platform", Type: StopType_Platform, Parent: &station, Level: &platformLevel}
+
+	if diff := cmp.Diff(actual.Levels, []Level{street, mezzanine, platformLevel}); diff != "" {
+		t.Errorf("levels not the same: %s", diff)
+	}
+	if diff := cmp.Diff(actual.Stops, []Stop{
+		station,
+		entrance,
+		node,
+		platform,
+		{Id: "unknown_level", Type: StopType_GenericNode, Parent: &station},
+	}); diff != "" {
+		t.Errorf("stops not the same: %s", diff)
+	}
+	if diff := cmp.Diff(actual.Pathways, []Pathway{
+		{
+			Id:                   "stairs",
+			From:                 &entrance,
+			To:                   &node,
+			Mode:                 PathwayMode_Stairs,
+			IsBidirectional:      true,
+			Length:               ptr(10.5),
+			TraversalTime:        ptr(time.Minute),
+			StairCount:           ptr(int32(-20)),
+			MinWidth:             ptr(1.5),
+			SignpostedAs:         "To platforms",
+			ReversedSignpostedAs: "To street",
+		},
+		{
+			Id:            "escalator",
+			From:          &node,
+			To:            &platform,
+			Mode:          PathwayMode_Escalator,
+			TraversalTime: ptr(45 * time.Second),
+		},
+	}); diff != "" {
+		t.Errorf("pathways not the same: %s", diff)
+	}
+	var pathwayWarnings []warnings.StaticWarningKind
+	for _, warning := range actual.Warnings {
+		if warning.File == constants.PathwaysFile {
+			pathwayWarnings = append(pathwayWarnings, warning.Kind)
+		}
+	}
+	if diff := cmp.Diff(pathwayWarnings, []warnings.StaticWarningKind{
+		warnings.InvalidReference{Column: "to_stop_id", Value: "unknown"},
+		warnings.InvalidValue{Column: "pathway_mode", Value: "8"},
+		warnings.InvalidValue{Column: "is_bidirectional", Value: "yes"},
+	}); diff != "" {
+		t.Errorf("pathway warnings not the same: %s", diff)
+	}
+}
+
+func TestFindPathwayRoute(t *testing.T) {
+	content := newZipBuilder().add(
+		"stops.txt",
+		"stop_id,location_type,parent_station,wheelchair_boarding",
+		"station,1,,1",
+		"entrance,2,station,",
+		"side_entrance,2,station,2",
+		"node,3,station,",
+		"platform,0,station,",
+		"other_platform,0,station,",
+	).add(
+		"pathways.txt",
+		"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,length,traversal_time,stair_count",
+		"stairs,entrance,node,2,1,,30,",
+		"walkway,entrance,node,1,1,120,,",
+		"escalator,node,platform,4,0,,20,",
+		"elevator,platform,node,5,1,,60,",
+		"side_walkway,side_entrance,platform,1,1,,10,",
+		"one_way,other_platform,node,1,0,,10,",
+		"walkway_with_steps,other_platform,entrance,1,0,,15,3",
+	).build()
+
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	stopIDToStop := map[string]*Stop{}
+	for i := range static.Stops {
+		stopIDToStop[static.Stops[i].Id] = &static.Stops[i]
+	}
+	pathwayIDToPathway := map[string]*Pathway{}
+	for i := range static.Pathways {
+		pathwayIDToPathway[static.Pathways[i].Id] = &static.Pathways[i]
+	}
+	type step struct {
+		pathwayID string
+		reversed  bool
+	}
+
+	for _, tc := range []struct {
+		desc                  string
+		from                  string
+		to                    string
+		opts                  PathwayRouteOptions
+		expectedSteps         []step
+		expectedTraversalTime time.Duration
+		expectedNoRoute       bool
+	}{
+		{
+			desc:                  "fastest route",
+			from:                  "entrance",
+			to:                    "platform",
+			expectedSteps:         []step{{"stairs", false}, {"escalator", false}},
+			expectedTraversalTime: 50 * time.Second,
+		},
+		{
+			desc:                  "step free route",
+			from:                  "entrance",
+			to:                    "platform",
+			opts:                  PathwayRouteOptions{StepFree: true},
+			expectedSteps:         []step{{"walkway", false}, {"elevator", true}},
+			expectedTraversalTime: 160 * time.Second,
+		},
+		{
+			desc:                  "custom walking speed",
+			from:                  "entrance",
+			to:                    "platform",
+			opts:                  PathwayRouteOptions{StepFree: true, WalkingSpeed: 2},
+			expectedSteps:         []step{{"walkway", false}, {"elevator", true}},
+			expectedTraversalTime: 120 * time.Second,
+		},
+		{
+			desc:                  "bidirectional pathways traversed in reverse",
+			from:                  "platform",
+			to:                    "entrance",
+			expectedSteps:         []step{{"elevator", false}, {"stairs", true}},
+			expectedTraversalTime: 90 * time.Second,
+		},
+		{
+			desc:            "step free route avoids stops without wheelchair boarding",
+			from:            "platform",
+			to:              "side_entrance",
+			opts:            PathwayRouteOptions{StepFree: true},
+			expectedNoRoute: true,
+		},
+		{
+			desc:                  "stop without wheelchair boarding used when not step free",
+			from:                  "platform",
+			to:                    "side_entrance",
+			expectedSteps:         []step{{"side_walkway", true}},
+			expectedTraversalTime: 10 * time.Second,
+		},
+		{
+			desc:                  "walkway with steps used when not step free",
+			from:                  "other_platform",
+			to:                    "entrance",
+			expectedSteps:         []step{{"walkway_with_steps", false}},
+			expectedTraversalTime: 15 * time.Second,
+		},
+		{
+			desc:                  "step free route avoids walkways with steps",
+			from:                  "other_platform",
+			to:                    "entrance",
+			opts:                  PathwayRouteOptions{StepFree: true},
+			expectedSteps:         []step{{"one_way", false}, {"walkway", true}},
+			expectedTraversalTime: 110 * time.Second,
+		},
+		{
+			desc:            "one way pathway",
+			from:            "node",
+			to:              "other_platform",
+			expectedNoRoute: true,
+		},
+		{
+			desc: "same stop",
+			from: "node",
+			to:   "node",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			route, ok := static.FindPathwayRoute(stopIDToStop[tc.from], stopIDToStop[tc.to], tc.opts)
+			if ok == tc.expectedNoRoute {
+				t.Fatalf("route found: got %t, want %t", ok, !tc.expectedNoRoute)
+			}
+			if !ok {
+				return
+			}
+			var expectedSteps []PathwayRouteStep
+			for _, s := range tc.expectedSteps {
+				expectedSteps = append(expectedSteps, PathwayRouteStep{
+					Pathway:  pathwayIDToPathway[s.pathwayID],
+					Reversed: s.reversed,
+				})
+			}
+			if diff := cmp.Diff(route, &PathwayRoute{
+				Steps:         expectedSteps,
+				TraversalTime: tc.expectedTraversalTime,
+			}); diff != "" {
+				t.Errorf("route not the same: %s", diff)
+			}
+		})
+	}
+}
@@ -22,21 +22,13 @@ func main() {
 func run() error {
 	flag.Parse()
 	gtfsFiles := flag.Args()
-	var gtfsBytes [][]byte
-	for _, gtfsFile := range gtfsFiles {
-		b, err := os.ReadFile(gtfsFile)
-		if err != nil {
-			return err
-		}
-		gtfsBytes = append(gtfsBytes, b)
-	}
 
 	fmt.Println("starting profile")
 	var profile bytes.Buffer
 	pprof.StartCPUProfile(&profile)
-	for i, in := range gtfsBytes {
-		fmt.Printf("parsing file %d/%d\n", i+1, len(gtfsBytes))
-		_, err := gtfs.ParseStatic(in, gtfs.ParseStaticOptions{})
+	for i, gtfsFile := range gtfsFiles {
+		fmt.Printf("parsing file %d/%d\n", i+1, len(gtfsFiles))
+		_, err := gtfs.ParseStaticFile(gtfsFile, gtfs.ParseStaticOptions{})
 		if err != nil {
 			return err
 		}
@@ -8,6 +8,7 @@ import (
 	"github.com/OneBusAway/go-gtfs/internal/testutil"
 	gtfsrt "github.com/OneBusAway/go-gtfs/proto"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -914,3 +915,46 @@ func parseDirectionID_GTFSRealtimeRaw(d gtfs.DirectionID) *uint32 {
 func ptr[T any](t T) *T {
 	return &t
 }
+
+func FuzzParseRealtime(f *testing.F) {
+	version := "2.0"
+	for _, entities := range [][]*gtfsrt.FeedEntity{
+		nil,
+		{
+			{
+				Id: ptr("1"),
+				TripUpdate: &gtfsrt.TripUpdate{
+					Trip: &gtfsrt.TripDescriptor{
+						TripId:    ptr(tripID1),
+						StartTime: ptr("25:00:00"),
+						StartDate: ptr("20230101"),
+					},
+					StopTimeUpdate: []*gtfsrt.TripUpdate_StopTimeUpdate{
+						{StopId: ptr(stopID1)},
+					},
+				},
+				Vehicle: &gtfsrt.VehiclePosition{
+					Vehicle: &gtfsrt.VehicleDescriptor{Id: ptr(vehicleID1)},
+				},
+				Alert: &gtfsrt.Alert{
+					InformedEntity: []*gtfsrt.EntitySelector{
+						{Trip: &gtfsrt.TripDescriptor{TripId: ptr(tripID2)}},
+					},
+				},
+			},
+		},
+	} {
+		b, err := proto.Marshal(&gtfsrt.FeedMessage{
+			Header: &gtfsrt.FeedHeader{GtfsRealtimeVersion: &version},
+			Entity: entities,
+		})
+		if err != nil {
+			f.Fatalf("failed to marshal GTFS-RT message: %s", err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// Only panics are failures; most inputs are not valid GTFS-RT messages.
+		gtfs.ParseRealtime(b, &gtfs.ParseRealtimeOptions{})
+	})
+}
@@ -4,11 +4,16 @@ package gtfs
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -26,9 +31,45 @@ type Static struct {
 	Services  []Service
 	Trips     []ScheduledTrip
 	Shapes    []Shape
+	Levels    []Level
+	Pathways  []Pathway
+
+	// GTFS-Flex data.
+	Locations      []Location
+	LocationGroups []LocationGroup
+	BookingRules   []BookingRule
+
+	FareAttributes []FareAttribute
+	FareRules      []FareRule
+	FareZones      []FareZone
+
+	RiderCategories   []RiderCategory
+	FareMedia         []FareMedia
+	FareProducts      []FareProduct
+	FareLegRules      []FareLegRule
+	FareLegJoinRules  []FareLegJoinRule
+	FareTransferRules []FareTransferRule
+	Timeframes        []Timeframe
+	Areas             []Area
+
+	// If true, fare_leg_rules.txt has a rule_priority column. Empty fields of fare leg rules then match
+	// every leg and the rules with the highest priority take precedence.
+	FareLegRulePriorities bool
+
+	// Feed information from the feed_info.txt file, or nil if the feed does not contain this file.
+	FeedInfo *FeedInfo
+
+	Attributions []Attribution
+
+	// Translations from the translations.txt file. Use a Translator to look up translated values.
+	Translations []Translation
 
 	// Warnings raised during GTFS static parsing.
 	Warnings []warnings.StaticWarning
+
+	// Maps from IDs to entities, built when the feed is parsed and used by the ByID methods.
+	// It is nil if the Static was not parsed, in which case the ByID methods scan the slices.
+	IDs *StaticIDs
 }
 
 // Agency corresponds to a single row in the agency.txt file.
@@ -43,6 +84,23 @@ type Agency struct {
 	Email    string
 }
 
+// FeedInfo corresponds to the single row in the feed_info.txt file.
+type FeedInfo struct {
+	PublisherName   string
+	PublisherUrl    string
+	Language        string
+	DefaultLanguage string
+	// Start of the period the feed is valid for, in the agency timezone.
+	// This is the zero time if the feed does not specify a start date.
+	StartDate time.Time
+	// End of the period the feed is valid for, in the agency timezone.
+	// This is the zero time if the feed does not specify an end date.
+	EndDate      time.Time
+	Version      string
+	ContactEmail string
+	ContactUrl   string
+}
+
 // Route corresponds to a single row in the routes.txt file.
 type Route struct {
 	Id                string
@@ -57,6 +115,8 @@ type Route struct {
 	SortOrder         *int32
 	ContinuousPickup  PickupDropOffPolicy
 	ContinuousDropOff PickupDropOffPolicy
+	// ID of the fare network the route belongs to, from either routes.txt or route_networks.txt.
+	NetworkId string
 }
 
 type Stop struct {
@@ -73,6 +133,7 @@ type Stop struct {
 	Timezone           string
 	WheelchairBoarding WheelchairBoarding
 	PlatformCode       string
+	Level              *Level
 }
 
 // Root returns the root stop.
@@ -85,9 +146,28 @@ func (stop *Stop) Root() *Stop {
 	}
 }
 
+// EffectiveTimezone returns the timezone of the stop, or of its parent station if the stop has none.
+// It is empty if neither has a timezone, in which case the stop is in the agency timezone.
+func (stop *Stop) EffectiveTimezone() string {
+	for ; stop != nil; stop = stop.Parent {
+		if stop.Timezone != "" {
+			return stop.Timezone
+		}
+	}
+	return ""
+}
+
+// Transfer corresponds to a single row in the transfers.txt file.
+//
+// The stops are nil for recommended and in-seat transfers that do not specify them. The routes and
+// trips are nil unless the transfer only applies to specific routes or trips.
 type Transfer struct {
 	From            *Stop
 	To              *Stop
+	FromRoute       *Route
+	ToRoute         *Route
+	FromTrip        *ScheduledTrip
+	ToTrip          *ScheduledTrip
 	Type            TransferType
 	MinTransferTime *int32
 }
@@ -123,10 +203,22 @@ type ScheduledTrip struct {
 }
 
 type ScheduledStopTime struct {
-	Trip                  *ScheduledTrip
-	Stop                  *Stop
-	ArrivalTime           time.Duration
-	DepartureTime         time.Duration
+	// Trip the stop time belongs to.
+	Trip *ScheduledTrip
+	// Stop at which the vehicle stops. For GTFS-Flex stop times exactly one of Stop, LocationGroup and
+	// Location is set.
+	Stop          *Stop
+	LocationGroup *LocationGroup
+	Location      *Location
+	ArrivalTime   time.Duration
+	DepartureTime time.Duration
+	// Whether the arrival time was given in the feed. If only one of the arrival and departure times
+	// is given, its value is also used for the other time, which is not marked as given. If neither is
+	// given, both times were interpolated from the surrounding stop times, or are zero if they could not
+	// be interpolated.
+	HasArrivalTime bool
+	// Whether the departure time was given in the feed; see HasArrivalTime.
+	HasDepartureTime      bool
 	StopSequence          int
 	Headsign              string
 	PickupType            PickupDropOffPolicy
@@ -135,6 +227,58 @@ type ScheduledStopTime struct {
 	ContinuousDropOff     PickupDropOffPolicy
 	ShapeDistanceTraveled *float64
 	ExactTimes            bool
+	// Start of the GTFS-Flex window in which riders can be picked up or dropped off.
+	StartPickupDropOffWindow *time.Duration
+	// End of the GTFS-Flex window in which riders can be picked up or dropped off.
+	EndPickupDropOffWindow *time.Duration
+	PickupBookingRule      *BookingRule
+	DropOffBookingRule     *BookingRule
+}
+
+// ArrivalInstant returns the instant of the arrival time on the given service date.
+//
+// As in the GTFS specification, the arrival time is measured from noon minus 12 hours on the service date
+// in the timezone of serviceDate, which should be the agency timezone as in the dates of Service.
+// The result is expressed in the timezone of the stop if it has one, so that its clock time is the local time
+// at the stop.
+func (stopTime *ScheduledStopTime) ArrivalInstant(serviceDate time.Time) time.Time {
+	return stopTimeInstant(serviceDate, stopTime.ArrivalTime, stopTime.Stop)
+}
+
+// DepartureInstant returns the instant of the departure time on the given service date.
+// See ArrivalInstant for details.
+func (stopTime *ScheduledStopTime) DepartureInstant(serviceDate time.Time) time.Time {
+	return stopTimeInstant(serviceDate, stopTime.DepartureTime, stopTime.Stop)
+}
+
+func stopTimeInstant(serviceDate time.Time, d time.Duration, stop *Stop) time.Time {
+	year, month, day := serviceDate.Date()
+	t := time.Date(year, month, day, 12, 0, 0, 0, serviceDate.Location()).Add(-12 * time.Hour).Add(d)
+	if timezone := stop.EffectiveTimezone(); timezone != "" {
+		if location, err := loadLocation(timezone); err == nil {
+			t = t.In(location)
+		}
+	}
+	return t
+}
+
+// locations caches the results of loadLocation by timezone name.
+var locations sync.Map
+
+type loadedLocation struct {
+	location *time.Location
+	err      error
+}
+
+// loadLocation is time.LoadLocation with a cache, as time.LoadLocation reads the timezone database on
+// every call and the same few timezones are loaded for every stop time.
+func loadLocation(name string) (*time.Location, error) {
+	if loaded, ok := locations.Load(name); ok {
+		return loaded.(loadedLocation).location, loaded.(loadedLocation).err
+	}
+	location, err := time.LoadLocation(name)
+	locations.Store(name, loadedLocation{location: location, err: err})
+	return location, err
 }
 
 type ShapePoint struct {
@@ -159,36 +303,258 @@ type ParseStaticOptions struct {
 	// If true, wheelchair boarding information is inherited from parent station
 	// when unspecified for a child stop/platform, entrance, or exit.
 	InheritWheelchairBoarding bool
+
+	// Maximum number of files to parse concurrently.
+	// Files that do not depend on each other, like shapes.txt and stops.txt, are parsed in parallel.
+	// The result is the same as when parsing sequentially.
+	// If zero or one, files are parsed sequentially.
+	Parallelism int
+
+	// Files to skip.
+	//
+	// Skipped files are treated as if they were not in the feed, even if they are required.
+	// Fields populated from a skipped file are left empty; for example, if stop_times.txt is skipped
+	// ScheduledTrip.StopTimes is nil, and if shapes.txt is skipped ScheduledTrip.Shape is nil.
+	// Files that reference a skipped file are still parsed, but rows that reference records in the skipped
+	// file are dropped as if the reference was invalid. For example, if trips.txt is skipped every row
+	// of stop_times.txt is dropped, so stop_times.txt should generally be skipped too.
+	// No warnings are raised for references to records in skipped files.
+	SkipFiles []constants.StaticFile
+
+	// If true, only the files describing the physical network are parsed:
+	// agency.txt, feed_info.txt, routes.txt, levels.txt, stops.txt, pathways.txt, transfers.txt and translations.txt.
+	// All other files are skipped as described in SkipFiles.
+	//
+	// Because trips.txt is skipped, transfers that reference trips are dropped.
+	TopologyOnly bool
+
+	// Restricts the routes, trips and stops that are parsed.
+	// See StaticFilter for details.
+	Filter StaticFilter
+
+	// If non-nil, the stop times of each trip are passed to this function instead of being stored in
+	// ScheduledTrip.StopTimes, which is left nil.
+	// This greatly reduces memory usage for large feeds.
+	//
+	// The function is called exactly once for each trip, sequentially.
+	// The stop times are sorted and interpolated, and their stops are resolved, exactly as they would be in
+	// ScheduledTrip.StopTimes. Trips without stop times are visited last with a nil slice.
+	// The slice must not be retained after the function returns.
+	//
+	// The rows of each trip must be contiguous in stop_times.txt, which is the case in almost all feeds.
+	// Rows of a trip appearing after rows of other trips are skipped.
+	// Trips removed by the filter are not visited, and if a filter is set the trips in the returned
+	// Static may be copies of the visited trips.
+	VisitStopTimes func(trip *ScheduledTrip, stopTimes []ScheduledStopTime)
+
+	// If true, missing stop times in feeds without shape_dist_traveled are interpolated in proportion to
+	// the distance along the shape of the trip, or to the straight-line distance between stops if the
+	// trip has no shape.
+	// Otherwise they are spaced evenly between the surrounding stop times.
+	InterpolateByGeometry bool
+
+	// If true, parsing fails with a *StrictError if any warning has severity error or fatal.
+	// Otherwise warnings are only reported in Static.Warnings.
+	Strict bool
+
+	// Overrides Strict for the warnings with the given codes.
+	// If the value for a code is true, warnings with that code make parsing fail even when not strict.
+	// If the value is false, warnings with that code never make parsing fail.
+	StrictCodes map[string]bool
+
+	// ID given to the agency if the feed has a single agency without an agency_id.
+	// If empty, or if the feed has several agencies, the ID is the name of the agency followed by "_id".
+	DefaultAgencyID string
+
+	// Timezone used to interpret the dates in the feed, like the start and end dates of services, and the
+	// times of fare timeframes.
+	// If nil, the timezone of the first agency is used, or UTC if it is invalid.
+	Timezone *time.Location
+}
+
+// topologyFiles are the files parsed when the TopologyOnly option is set.
+var topologyFiles = map[constants.StaticFile]bool{
+	constants.AgencyFile:       true,
+	constants.FeedInfoFile:     true,
+	constants.RoutesFile:       true,
+	constants.LevelsFile:       true,
+	constants.StopsFile:        true,
+	constants.PathwaysFile:     true,
+	constants.TransfersFile:    true,
+	constants.TranslationsFile: true,
+}
+
+func (opts *ParseStaticOptions) skipFile(file constants.StaticFile) bool {
+	if opts.TopologyOnly && !topologyFiles[file] {
+		return true
+	}
+	for _, skipFile := range opts.SkipFiles {
+		if skipFile == file {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedFiles maps the columns of invalid reference warnings to the files that contain the referenced records.
+var referencedFiles = map[string][]constants.StaticFile{
+	"agency_id":                {constants.AgencyFile},
+	"route_id":                 {constants.RoutesFile},
+	"from_route_id":            {constants.RoutesFile},
+	"to_route_id":              {constants.RoutesFile},
+	"trip_id":                  {constants.TripsFile},
+	"from_trip_id":             {constants.TripsFile},
+	"to_trip_id":               {constants.TripsFile},
+	"stop_id":                  {constants.StopsFile},
+	"from_stop_id":             {constants.StopsFile},
+	"to_stop_id":               {constants.StopsFile},
+	"level_id":                 {constants.LevelsFile},
+	"shape_id":                 {constants.ShapesFile},
+	"service_id":               {constants.CalendarFile, constants.CalendarDatesFile},
+	"prior_notice_service_id":  {constants.CalendarFile, constants.CalendarDatesFile},
+	"location_group_id":        {constants.LocationGroupsFile},
+	"location_id":              {constants.LocationsFile},
+	"pickup_booking_rule_id":   {constants.BookingRulesFile},
+	"drop_off_booking_rule_id": {constants.BookingRulesFile},
+	"fare_id":                  {constants.FareAttributesFile},
+	"area_id":                  {constants.AreasFile},
+	"from_area_id":             {constants.AreasFile},
+	"to_area_id":               {constants.AreasFile},
+	"from_timeframe_group_id":  {constants.TimeframesFile},
+	"to_timeframe_group_id":    {constants.TimeframesFile},
+	"rider_category_id":        {constants.RiderCategoriesFile},
+	"fare_media_id":            {constants.FareMediaFile},
+	"fare_product_id":          {constants.FareProductsFile},
+}
+
+// removeSkippedReferenceWarnings removes the warnings for references to records in skipped files,
+// as the references may be valid.
+func (opts *ParseStaticOptions) removeSkippedReferenceWarnings(w []warnings.StaticWarning) []warnings.StaticWarning {
+	var result []warnings.StaticWarning
+	for _, warning := range w {
+		if reference, ok := warning.Kind.(warnings.InvalidReference); ok && opts.skipsReferencedFile(reference.Column) {
+			continue
+		}
+		result = append(result, warning)
+	}
+	return result
+}
+
+func (opts *ParseStaticOptions) skipsReferencedFile(column string) bool {
+	for _, file := range referencedFiles[column] {
+		if opts.skipFile(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// failsParsing returns true if the warning makes parsing fail.
+func (opts *ParseStaticOptions) failsParsing(warning *warnings.StaticWarning) bool {
+	if strict, ok := opts.StrictCodes[warning.Kind.Code()]; ok {
+		return strict
+	}
+	return opts.Strict && warning.Kind.Severity() >= warnings.Severity_Error
+}
+
+// StrictError is returned when parsing fails because of warnings in strict mode.
+type StrictError struct {
+	// The warnings that made parsing fail.
+	Warnings []warnings.StaticWarning
+}
+
+func (err *StrictError) Error() string {
+	const maxWarnings = 10
+	var b strings.Builder
+	fmt.Fprintf(&b, "parsing failed because of %d warnings:", len(err.Warnings))
+	for i, warning := range err.Warnings {
+		if i == maxWarnings {
+			fmt.Fprintf(&b, "\n- and %d more", len(err.Warnings)-maxWarnings)
+			break
+		}
+		fmt.Fprintf(&b, "\n- %s row %d: %s", warning.File, warning.RowNumber, warning.Kind.Error())
+	}
+	return b.String()
 }
 
 // ParseStatic parses the content as a GTFS static feed.
 func ParseStatic(content []byte, opts ParseStaticOptions) (*Static, error) {
-	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	return ParseStaticReader(bytes.NewReader(content), int64(len(content)), opts)
+}
+
+// ParseStaticReader parses a GTFS static feed from a zip archive of the given size.
+//
+// Unlike ParseStatic, the archive does not need to be loaded into memory before parsing.
+func ParseStaticReader(r io.ReaderAt, size int64, opts ParseStaticOptions) (*Static, error) {
+	reader, err := zip.NewReader(r, size)
 	if err != nil {
 		return nil, err
 	}
-	result := &Static{}
-	fileNameToFile := map[constants.StaticFile]*zip.File{}
-	for _, file := range reader.File {
-		fileNameToFile[constants.StaticFile(file.Name)] = file
+	return ParseStaticFS(reader, opts)
+}
+
+// ParseStaticFile parses the GTFS static feed at the given path, which is either a zip archive
+// or a directory containing the feed's files.
+func ParseStaticFile(path string, opts ParseStaticOptions) (*Static, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
 	}
+	if info.IsDir() {
+		return ParseStaticFS(os.DirFS(path), opts)
+	}
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ParseStaticFS(reader, opts)
+}
+
+// ParseStaticFS parses a GTFS static feed whose files are in the root of the file system.
+//
+// If the root of the file system contains no .txt files and a single directory, the files
+// in that directory are parsed instead.
+// This handles zip archives in which the feed's files have been placed inside a folder.
+func ParseStaticFS(fsys fs.FS, opts ParseStaticOptions) (*Static, error) {
+	fsys, err := feedRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+	result := &Static{}
+	ids := &StaticIDs{}
 	serviceIdToService := map[string]Service{}
-	shapeIdToShape := map[string]*Shape{}
-	tripIdToScheduledTrip := map[string]*ScheduledTrip{}
 	timezone := time.UTC
-	for _, table := range []struct {
-		File        constants.StaticFile
-		Action      func(file *csv.File) []warnings.StaticWarning
-		PostProcess func()
-		Optional    bool
-	}{
+	var locationWarnings []warnings.StaticWarning
+	if !opts.skipFile(constants.LocationsFile) {
+		result.Locations, locationWarnings, err = readLocationsFile(fsys)
+		if err != nil {
+			return nil, err
+		}
+		ids.locations = indexByID(result.Locations, (*Location).id)
+	}
+	filter := newFilterState(&opts.Filter)
+	var visit func(trip *ScheduledTrip, stopTimes []ScheduledStopTime)
+	var visited *visitedStopTimes
+	if opts.VisitStopTimes != nil {
+		visited = newVisitedStopTimes()
+		visit = func(trip *ScheduledTrip, stopTimes []ScheduledStopTime) {
+			if visited.add(trip, stopTimes, &opts.Filter) {
+				opts.VisitStopTimes(trip, stopTimes)
+			}
+		}
+	}
+	tables := []staticTable{
 		{
 			File: constants.AgencyFile,
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				result.Agencies, w = parseAgencies(file)
-				if len(result.Agencies) > 0 {
+				result.Agencies, w = parseAgencies(file, opts.DefaultAgencyID)
+				ids.agencies = indexByID(result.Agencies, (*Agency).id)
+				if opts.Timezone != nil {
+					timezone = opts.Timezone
+				} else if len(result.Agencies) > 0 {
 					var err error
-					timezone, err = time.LoadLocation(result.Agencies[0].Timezone)
+					timezone, err = loadLocation(result.Agencies[0].Timezone)
 					if err != nil {
 						timezone = time.UTC
 					}
@@ -197,112 +563,450 @@ func ParseStatic(content []byte, opts ParseStaticOptions) (*Static, error) {
 			},
 		},
 		{
-			File: "routes.txt",
+			File:      constants.FeedInfoFile,
+			DependsOn: []constants.StaticFile{constants.AgencyFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				result.Routes = parseRoutes(file, result.Agencies)
+				result.FeedInfo, w = parseFeedInfo(file, timezone)
 				return
 			},
+			Optional: true,
 		},
 		{
-			File: "stops.txt",
+			File:      constants.RoutesFile,
+			DependsOn: []constants.StaticFile{constants.AgencyFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				result.Stops = parseStops(file, opts.InheritWheelchairBoarding)
+				result.Routes, w = parseRoutes(file, result.Agencies, ids, filter)
+				ids.routes = indexByID(result.Routes, (*Route).id)
 				return
 			},
 		},
 		{
-			File: "transfers.txt",
+			File: constants.LevelsFile,
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				result.Transfers = parseTransfers(file, result.Stops)
+				result.Levels, w = parseLevels(file)
+				ids.levels = indexByID(result.Levels, (*Level).id)
 				return
 			},
 			Optional: true,
 		},
 		{
-			File: "calendar.txt",
+			File:      constants.StopsFile,
+			DependsOn: []constants.StaticFile{constants.LevelsFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				parseCalendar(file, serviceIdToService, timezone)
+				result.Stops, w = parseStops(file, ids, opts.InheritWheelchairBoarding)
+				ids.stops = indexByID(result.Stops, (*Stop).id)
 				return
 			},
-			Optional: true,
 		},
 		{
-			File: "calendar_dates.txt",
+			File:      constants.PathwaysFile,
+			DependsOn: []constants.StaticFile{constants.StopsFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				parseCalendarDates(file, serviceIdToService, timezone)
+				result.Pathways, w = parsePathways(file, ids)
 				return
 			},
+			Optional: true,
+		},
+		{
+			File:      constants.CalendarFile,
+			DependsOn: []constants.StaticFile{constants.AgencyFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				return parseCalendar(file, serviceIdToService, timezone)
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.CalendarDatesFile,
+			DependsOn: []constants.StaticFile{constants.AgencyFile, constants.CalendarFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				return parseCalendarDates(file, serviceIdToService, timezone)
+			},
 			PostProcess: func() {
 				for _, service := range serviceIdToService {
 					result.Services = append(result.Services, service)
 				}
+				sort.Slice(result.Services, func(i, j int) bool {
+					return result.Services[i].Id < result.Services[j].Id
+				})
+				ids.services = indexByID(result.Services, (*Service).id)
 			},
 			Optional: true,
 		},
 		{
-			File: "shapes.txt",
+			File: constants.ShapesFile,
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				result.Shapes = parseShapes(file)
-				for idx, shape := range result.Shapes {
-					shapeIdToShape[shape.ID] = &result.Shapes[idx]
-				}
+				result.Shapes, w = parseShapes(file)
+				ids.shapes = indexByID(result.Shapes, (*Shape).id)
 				return
 			},
 			Optional: true,
 		},
 		{
-			File: "trips.txt",
+			File:      constants.TripsFile,
+			DependsOn: []constants.StaticFile{constants.RoutesFile, constants.CalendarDatesFile, constants.ShapesFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				result.Trips = parseScheduledTrips(file, result.Routes, result.Services, shapeIdToShape)
-				for idx, trip := range result.Trips {
-					tripIdToScheduledTrip[trip.ID] = &result.Trips[idx]
-				}
+				result.Trips, w = parseScheduledTrips(file, ids, filter)
+				ids.trips = indexByID(result.Trips, (*ScheduledTrip).id)
 				return
 			},
 		},
 		{
-			File: "frequencies.txt",
+			File:      constants.TransfersFile,
+			DependsOn: []constants.StaticFile{constants.StopsFile, constants.RoutesFile, constants.TripsFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				parseFrequencies(file, tripIdToScheduledTrip)
+				result.Transfers, w = parseTransfers(file, ids)
 				return
 			},
 			Optional: true,
 		},
 		{
-			File: "stop_times.txt",
+			File:      constants.AttributionsFile,
+			DependsOn: []constants.StaticFile{constants.AgencyFile, constants.RoutesFile, constants.TripsFile},
 			Action: func(file *csv.File) (w []warnings.StaticWarning) {
-				parseScheduledStopTimes(file, result.Stops, result.Trips)
+				result.Attributions, w = parseAttributions(file, ids)
 				return
 			},
+			Optional: true,
 		},
-	} {
-		if table.PostProcess == nil {
-			table.PostProcess = func() {}
+		{
+			File:      constants.FrequenciesFile,
+			DependsOn: []constants.StaticFile{constants.TripsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				return parseFrequencies(file, ids.trips)
+			},
+			Optional: true,
+		},
+		{
+			File: constants.LocationGroupsFile,
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.LocationGroups, w = parseLocationGroups(file)
+				ids.locationGroups = indexByID(result.LocationGroups, (*LocationGroup).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.LocationGroupStopsFile,
+			DependsOn: []constants.StaticFile{constants.LocationGroupsFile, constants.StopsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				return parseLocationGroupStops(file, ids)
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.BookingRulesFile,
+			DependsOn: []constants.StaticFile{constants.CalendarDatesFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.BookingRules, w = parseBookingRules(file, ids)
+				ids.bookingRules = indexByID(result.BookingRules, (*BookingRule).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.StopTimesFile,
+			DependsOn: []constants.StaticFile{constants.StopsFile, constants.TripsFile, constants.LocationGroupStopsFile, constants.BookingRulesFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				// Trips are visited as they are read, so whether the feed has shape distances is found first.
+				hasShapeDist := visit != nil && hasShapeDistances(fsys)
+				return parseScheduledStopTimes(file, ids, result.Trips, opts.Parallelism, hasShapeDist, opts.InterpolateByGeometry, filter, visit)
+			},
+		},
+		{
+			File:      constants.FareAttributesFile,
+			DependsOn: []constants.StaticFile{constants.AgencyFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareAttributes, w = parseFareAttributes(file, ids)
+				ids.fareAttributes = indexByID(result.FareAttributes, (*FareAttribute).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.FareRulesFile,
+			DependsOn: []constants.StaticFile{constants.FareAttributesFile, constants.RoutesFile, constants.StopsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareRules, result.FareZones, w = parseFareRules(file, result.Stops, ids)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.RouteNetworksFile,
+			DependsOn: []constants.StaticFile{constants.RoutesFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				return parseRouteNetworks(file, ids)
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.TimeframesFile,
+			DependsOn: []constants.StaticFile{constants.CalendarDatesFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.Timeframes, w = parseTimeframes(file, ids)
+				ids.timeframeGroups = groupTimeframes(result.Timeframes)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File: constants.RiderCategoriesFile,
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.RiderCategories, w = parseRiderCategories(file)
+				ids.riderCategories = indexByID(result.RiderCategories, (*RiderCategory).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File: constants.FareMediaFile,
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareMedia, w = parseFareMedia(file)
+				ids.fareMedia = indexByID(result.FareMedia, (*FareMedia).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.FareProductsFile,
+			DependsOn: []constants.StaticFile{constants.RiderCategoriesFile, constants.FareMediaFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareProducts, w = parseFareProducts(file, ids)
+				ids.fareProducts = indexByID(result.FareProducts, (*FareProduct).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File: constants.AreasFile,
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.Areas, w = parseAreas(file)
+				ids.areas = indexByID(result.Areas, (*Area).id)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.StopAreasFile,
+			DependsOn: []constants.StaticFile{constants.AreasFile, constants.StopsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				return parseStopAreas(file, ids)
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.FareLegRulesFile,
+			DependsOn: []constants.StaticFile{constants.StopAreasFile, constants.TimeframesFile, constants.FareProductsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareLegRules, result.FareLegRulePriorities, w = parseFareLegRules(file, ids)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.FareLegJoinRulesFile,
+			DependsOn: []constants.StaticFile{constants.StopsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareLegJoinRules, w = parseFareLegJoinRules(file, ids)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File:      constants.FareTransferRulesFile,
+			DependsOn: []constants.StaticFile{constants.FareProductsFile},
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.FareTransferRules, w = parseFareTransferRules(file, ids)
+				return
+			},
+			Optional: true,
+		},
+		{
+			File: constants.TranslationsFile,
+			Action: func(file *csv.File) (w []warnings.StaticWarning) {
+				result.Translations, w = parseTranslations(file)
+				return
+			},
+			Optional: true,
+		},
+	}
+	for i := range tables {
+		tables[i].Skip = opts.skipFile(tables[i].File)
+	}
+	w, err := runStaticTables(fsys, tables, opts.Parallelism)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Filter.isActive() {
+		pruneStatic(result, &opts, visited)
+		ids = newStaticIDs(result)
+		w = filter.removeWarnings(w)
+	}
+	if opts.TopologyOnly || len(opts.SkipFiles) > 0 {
+		w = opts.removeSkippedReferenceWarnings(w)
+	}
+	result.Warnings = append(locationWarnings, w...)
+	result.IDs = ids
+	var failing []warnings.StaticWarning
+	for i := range result.Warnings {
+		if opts.failsParsing(&result.Warnings[i]) {
+			failing = append(failing, result.Warnings[i])
 		}
-		zipFile := fileNameToFile[table.File]
-		if zipFile == nil {
-			if table.Optional {
-				table.PostProcess()
-				continue
+	}
+	if len(failing) > 0 {
+		return nil, &StrictError{Warnings: failing}
+	}
+	return result, nil
+}
+
+// staticTable describes how a single file in a GTFS static feed is parsed.
+type staticTable struct {
+	File constants.StaticFile
+	// Files that must be parsed before this file, because the parser reads their parsed content.
+	// The files must appear earlier in the list of tables.
+	DependsOn   []constants.StaticFile
+	Action      func(file *csv.File) []warnings.StaticWarning
+	PostProcess func()
+	Optional    bool
+	// If true the file is treated as if it was not in the feed.
+	Skip bool
+}
+
+// runStaticTables parses the tables in the feed, running up to parallelism tables concurrently.
+//
+// The warnings and errors returned are the same as if the tables were parsed sequentially in order.
+// checkTableDependencies checks that every table only depends on earlier tables.
+//
+// The check is done whatever the parallelism, so that a wrong list of tables is found by every test.
+func checkTableDependencies(tables []staticTable) error {
+	earlier := map[constants.StaticFile]bool{}
+	for _, table := range tables {
+		for _, dependency := range table.DependsOn {
+			if !earlier[dependency] {
+				return fmt.Errorf("%s depends on %s which is not an earlier table", table.File, dependency)
 			}
-			return nil, fmt.Errorf("no %q file in GTFS static feed", table.File)
 		}
-		file, err := openCsvFile(table.File, zipFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read %q: %w", table.File, err)
+		earlier[table.File] = true
+	}
+	return nil
+}
+
+func runStaticTables(fsys fs.FS, tables []staticTable, parallelism int) ([]warnings.StaticWarning, error) {
+	if err := checkTableDependencies(tables); err != nil {
+		return nil, err
+	}
+	if parallelism <= 1 {
+		var result []warnings.StaticWarning
+		for _, table := range tables {
+			w, err := runStaticTable(fsys, table)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, w...)
 		}
-		w := table.Action(file)
-		table.PostProcess()
-		result.Warnings = append(result.Warnings, w...)
-		if err := file.Close(); err != nil {
-			return nil, fmt.Errorf("failed to read %q: %w", table.File, err)
+		return result, nil
+	}
+
+	fileToDone := map[constants.StaticFile]chan struct{}{}
+	for _, table := range tables {
+		fileToDone[table.File] = make(chan struct{})
+	}
+	type tableResult struct {
+		warnings []warnings.StaticWarning
+		err      error
+	}
+	results := make([]tableResult, len(tables))
+	semaphore := make(chan struct{}, parallelism)
+	var failed int32
+	var wg sync.WaitGroup
+	for i := range tables {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			table := tables[i]
+			defer close(fileToDone[table.File])
+			for _, dependency := range table.DependsOn {
+				<-fileToDone[dependency]
+			}
+			if atomic.LoadInt32(&failed) != 0 {
+				return
+			}
+			semaphore <- struct{}{}
+			w, err := runStaticTable(fsys, table)
+			<-semaphore
+			results[i] = tableResult{warnings: w, err: err}
+			if err != nil {
+				atomic.StoreInt32(&failed, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var result []warnings.StaticWarning
+	for _, r := range results {
+		if r.err != nil {
+			return nil, r.err
 		}
+		result = append(result, r.warnings...)
 	}
 	return result, nil
 }
 
-func openCsvFile(file constants.StaticFile, zipFile *zip.File) (*csv.File, error) {
-	content, err := zipFile.Open()
+func runStaticTable(fsys fs.FS, table staticTable) ([]warnings.StaticWarning, error) {
+	if table.PostProcess == nil {
+		table.PostProcess = func() {}
+	}
+	if table.Skip {
+		table.PostProcess()
+		return nil, nil
+	}
+	file, err := openCsvFile(fsys, table.File)
+	if errors.Is(err, fs.ErrNotExist) {
+		if table.Optional {
+			table.PostProcess()
+			return nil, nil
+		}
+		return nil, fmt.Errorf("no %q file in GTFS static feed", table.File)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", table.File, err)
+	}
+	w := table.Action(file)
+	table.PostProcess()
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", table.File, err)
+	}
+	return w, nil
+}
+
+// feedRoot returns the directory of the file system that contains the feed's files.
+func feedRoot(fsys fs.FS) (fs.FS, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if strings.HasSuffix(entry.Name(), ".txt") {
+				return fsys, nil
+			}
+			continue
+		}
+		// Created by the macOS archive utility.
+		if entry.Name() == "__MACOSX" {
+			continue
+		}
+		dirs = append(dirs, entry.Name())
+	}
+	if len(dirs) != 1 {
+		return fsys, nil
+	}
+	return fs.Sub(fsys, dirs[0])
+}
+
+func openCsvFile(fsys fs.FS, file constants.StaticFile) (*csv.File, error) {
+	content, err := fsys.Open(string(file))
 	if err != nil {
 		return nil, err
 	}
@@ -313,7 +1017,7 @@ func openCsvFile(file constants.StaticFile, zipFile *zip.File) (*csv.File, error
 	return f, nil
 }
 
-func parseAgencies(csv *csv.File) ([]Agency, []warnings.StaticWarning) {
+func parseAgencies(csv *csv.File, defaultAgencyID string) ([]Agency, []warnings.StaticWarning) {
 	var w []warnings.StaticWarning
 	idColumn := csv.OptionalColumn("agency_id")
 	nameColumn := csv.RequiredColumn("agency_name")
@@ -329,11 +1033,15 @@ func parseAgencies(csv *csv.File) ([]Agency, []warnings.StaticWarning) {
 	}
 
 	var agencies []Agency
+	var withoutID int
 	for csv.NextRow() {
 		name := nameColumn.Read()
+		id := idColumn.Read()
+		if id == "" {
+			id = fmt.Sprintf("%s_id", name)
+		}
 		agency := Agency{
-			// TODO: support specifying the default agency ID in the GTFS static parser settings
-			Id:       idColumn.ReadOr(fmt.Sprintf("%s_id", name)),
+			Id:       id,
 			Name:     name,
 			Url:      urlColumn.Read(),
 			Timezone: timezoneColumn.Read(),
@@ -349,12 +1057,27 @@ func parseAgencies(csv *csv.File) ([]Agency, []warnings.StaticWarning) {
 			}))
 			continue
 		}
+		if len(agencies) > 0 && agency.Timezone != agencies[0].Timezone {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.AgencyTimezoneMismatch{
+				AgencyID:         agency.Id,
+				Timezone:         agency.Timezone,
+				ExpectedTimezone: agencies[0].Timezone,
+			}))
+		}
+		if idColumn.Read() == "" {
+			withoutID++
+		}
 		agencies = append(agencies, agency)
 	}
+	// The default ID is only given to a single agency, as agencies with the same ID could not be told apart.
+	if defaultAgencyID != "" && len(agencies) == 1 && withoutID == 1 {
+		agencies[0].Id = defaultAgencyID
+	}
 	return agencies, w
 }
 
-func parseRoutes(csv *csv.File, agencies []Agency) []Route {
+func parseRoutes(csv *csv.File, agencies []Agency, ids *StaticIDs, filter *filterState) ([]Route, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
 	idColumn := csv.RequiredColumn("route_id")
 	agencyIDColumn := csv.OptionalColumn("agency_id")
 	colorColumn := csv.OptionalColumn("route_color")
@@ -367,10 +1090,10 @@ func parseRoutes(csv *csv.File, agencies []Agency) []Route {
 	sortOrderColumn := csv.OptionalColumn("route_sort_order")
 	continuousPickupColumn := csv.OptionalColumn("continuous_pickup")
 	continuousDropOffColumn := csv.OptionalColumn("continuous_drop_off")
+	networkIDColumn := csv.OptionalColumn("network_id")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return nil
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
 	}
 
 	var routes []Route
@@ -379,14 +1102,9 @@ func parseRoutes(csv *csv.File, agencies []Agency) []Route {
 		agencyID := agencyIDColumn.Read()
 		var agency *Agency
 		if agencyID != "" {
-			for i := range agencies {
-				if agencies[i].Id == agencyID {
-					agency = &agencies[i]
-					break
-				}
-			}
+			agency = ids.agencies[agencyID]
 			if agency == nil {
-				log.Printf("skipping route %s: no match for agency ID %s", routeID, agencyID)
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "agency_id", Value: agencyID}))
 				continue
 			}
 		} else if len(agencies) == 1 {
@@ -394,7 +1112,7 @@ func parseRoutes(csv *csv.File, agencies []Agency) []Route {
 			// which case the route's agency is the unique agency in the feed.
 			agency = &agencies[0]
 		} else {
-			log.Printf("skipping route %s: no agency ID provided but no unique agency", routeID)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.RouteWithoutAgency{RouteID: routeID}))
 			continue
 		}
 		route := Route{
@@ -410,14 +1128,18 @@ func parseRoutes(csv *csv.File, agencies []Agency) []Route {
 			SortOrder:         parseRouteSortOrder(sortOrderColumn.Read()),
 			ContinuousPickup:  parsePickupDropOffPolicy(continuousPickupColumn.ReadOr("")),
 			ContinuousDropOff: parsePickupDropOffPolicy(continuousDropOffColumn.ReadOr("")),
+			NetworkId:         networkIDColumn.Read(),
 		}
 		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping route %+v because of missing keys %s", route, missingKeys)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		if !filter.keepRoute(&route) {
 			continue
 		}
 		routes = append(routes, route)
 	}
-	return routes
+	return routes, w
 }
 
 func parseRouteSortOrder(raw string) *int32 {
@@ -432,7 +1154,8 @@ func parseRouteSortOrder(raw string) *int32 {
 	return &i32
 }
 
-func parseStops(csv *csv.File, inheritWheelchairBoarding bool) []Stop {
+func parseStops(csv *csv.File, ids *StaticIDs, inheritWheelchairBoarding bool) ([]Stop, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
 	idColumn := csv.RequiredColumn("stop_id")
 	codeColumn := csv.OptionalColumn("stop_code")
 	nameColumn := csv.OptionalColumn("stop_name")
@@ -446,10 +1169,10 @@ func parseStops(csv *csv.File, inheritWheelchairBoarding bool) []Stop {
 	wheelchairBoardingColumn := csv.OptionalColumn("wheelchair_boarding")
 	platformCodeColumn := csv.OptionalColumn("platform_code")
 	parentStationColumn := csv.OptionalColumn("parent_station")
+	levelIDColumn := csv.OptionalColumn("level_id")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return nil
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
 	}
 
 	var stops []Stop
@@ -477,9 +1200,15 @@ func parseStops(csv *csv.File, inheritWheelchairBoarding bool) []Stop {
 			PlatformCode:       platformCodeColumn.Read(),
 		}
 		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping stop %+v because of missing keys %s", stop, missingKeys)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
 			continue
 		}
+		if levelID := levelIDColumn.Read(); levelID != "" {
+			stop.Level = ids.levels[levelID]
+			if stop.Level == nil {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "level_id", Value: levelID}))
+			}
+		}
 		stopIdToIndex[stop.Id] = len(stops)
 		stops = append(stops, stop)
 	}
@@ -501,7 +1230,7 @@ func parseStops(csv *csv.File, inheritWheelchairBoarding bool) []Stop {
 		}
 	}
 
-	return stops
+	return stops, w
 }
 
 func parseFloat64(s string) *float64 {
@@ -515,51 +1244,131 @@ func parseFloat64(s string) *float64 {
 	return &f
 }
 
-func parseTransfers(csv *csv.File, stops []Stop) []Transfer {
-	fromStopIDColumn := csv.RequiredColumn("from_stop_id")
-	toStopIDColumn := csv.RequiredColumn("to_stop_id")
+func parseTransfers(csv *csv.File, ids *StaticIDs) ([]Transfer, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	fromStopIDColumn := csv.OptionalColumn("from_stop_id")
+	toStopIDColumn := csv.OptionalColumn("to_stop_id")
+	fromRouteIDColumn := csv.OptionalColumn("from_route_id")
+	toRouteIDColumn := csv.OptionalColumn("to_route_id")
+	fromTripIDColumn := csv.OptionalColumn("from_trip_id")
+	toTripIDColumn := csv.OptionalColumn("to_trip_id")
 	typeColumn := csv.OptionalColumn("transfer_type")
 	transferTimeColumn := csv.OptionalColumn("min_transfer_time")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return nil
-	}
-
-	stopIdToStop := map[string]*Stop{}
-	for i := range stops {
-		stopIdToStop[stops[i].Id] = &stops[i]
-	}
 	var transfers []Transfer
 	for csv.NextRow() {
-		fromStopID := fromStopIDColumn.Read()
-		toStopID := toStopIDColumn.Read()
-		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping transfer because of missing keys %s", missingKeys)
-			continue
+		transfer := Transfer{
+			Type:            parseTransferType(typeColumn.Read()),
+			MinTransferTime: parseInt32(transferTimeColumn.Read()),
 		}
-		fromStop, fromStopOk := stopIdToStop[fromStopID]
-		toStop, toStopOk := stopIdToStop[toStopID]
-		if !fromStopOk {
-			log.Printf("Skipping transfer because from_stop_id %q is invalid", fromStopID)
+		isInSeat := transfer.Type == TransferType_InSeat || transfer.Type == TransferType_InSeatNotAllowed
+		// The stops are only required for timed, minimum time and not possible transfers.
+		requiresStops := transfer.Type == TransferType_Timed || transfer.Type == TransferType_RequiresTime || transfer.Type == TransferType_NotPossible
+		valid := true
+		for _, reference := range []struct {
+			column   string
+			id       string
+			required bool
+			resolve  func(id string) bool
+		}{
+			{"from_stop_id", fromStopIDColumn.Read(), requiresStops, func(id string) (ok bool) {
+				transfer.From, ok = ids.stops[id]
+				return
+			}},
+			{"to_stop_id", toStopIDColumn.Read(), requiresStops, func(id string) (ok bool) {
+				transfer.To, ok = ids.stops[id]
+				return
+			}},
+			{"from_route_id", fromRouteIDColumn.Read(), false, func(id string) (ok bool) {
+				transfer.FromRoute, ok = ids.routes[id]
+				return
+			}},
+			{"to_route_id", toRouteIDColumn.Read(), false, func(id string) (ok bool) {
+				transfer.ToRoute, ok = ids.routes[id]
+				return
+			}},
+			{"from_trip_id", fromTripIDColumn.Read(), isInSeat, func(id string) (ok bool) {
+				transfer.FromTrip, ok = ids.trips[id]
+				return
+			}},
+			{"to_trip_id", toTripIDColumn.Read(), isInSeat, func(id string) (ok bool) {
+				transfer.ToTrip, ok = ids.trips[id]
+				return
+			}},
+		} {
+			if reference.id == "" {
+				if reference.required {
+					w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: []string{reference.column}}))
+					valid = false
+					break
+				}
+				continue
+			}
+			if !reference.resolve(reference.id) {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: reference.column, Value: reference.id}))
+				valid = false
+				break
+			}
+		}
+		if !valid {
 			continue
 		}
-		if !toStopOk {
-			log.Printf("Skipping transfer because to_stop_id %q is invalid", toStopID)
+		hasQualifiers := transfer.FromRoute != nil || transfer.ToRoute != nil || transfer.FromTrip != nil || transfer.ToTrip != nil
+		if transfer.From != nil && transfer.To != nil && transfer.From.Id == transfer.To.Id && !hasQualifiers {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.SameStopTransfer{StopID: transfer.From.Id}))
 			continue
 		}
-		if fromStop.Id == toStop.Id {
-			// log.Printf("Skipping transfer between the same stop %q", fromStop.Id)
+		transfers = append(transfers, transfer)
+	}
+	return transfers, w
+}
+
+func parseFeedInfo(csv *csv.File, timezone *time.Location) (*FeedInfo, []warnings.StaticWarning) {
+	publisherNameColumn := csv.RequiredColumn("feed_publisher_name")
+	publisherUrlColumn := csv.RequiredColumn("feed_publisher_url")
+	languageColumn := csv.RequiredColumn("feed_lang")
+	defaultLanguageColumn := csv.OptionalColumn("default_lang")
+	startDateColumn := csv.OptionalColumn("feed_start_date")
+	endDateColumn := csv.OptionalColumn("feed_end_date")
+	versionColumn := csv.OptionalColumn("feed_version")
+	contactEmailColumn := csv.OptionalColumn("feed_contact_email")
+	contactUrlColumn := csv.OptionalColumn("feed_contact_url")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var w []warnings.StaticWarning
+	var feedInfo *FeedInfo
+	for csv.NextRow() {
+		if feedInfo != nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.AdditionalRow{}))
 			continue
 		}
-		transfers = append(transfers, Transfer{
-			From:            fromStop,
-			To:              toStop,
-			Type:            parseTransferType(typeColumn.Read()),
-			MinTransferTime: parseInt32(transferTimeColumn.Read()),
-		})
+		feedInfo = &FeedInfo{
+			PublisherName:   publisherNameColumn.Read(),
+			PublisherUrl:    publisherUrlColumn.Read(),
+			Language:        languageColumn.Read(),
+			DefaultLanguage: defaultLanguageColumn.Read(),
+			Version:         versionColumn.Read(),
+			ContactEmail:    contactEmailColumn.Read(),
+			ContactUrl:      contactUrlColumn.Read(),
+		}
+		parseDate := func(name, raw string) time.Time {
+			if raw == "" {
+				return time.Time{}
+			}
+			t, err := parseTime(raw, timezone)
+			if err != nil {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: name, Value: raw}))
+				return time.Time{}
+			}
+			return t
+		}
+		feedInfo.StartDate = parseDate("feed_start_date", startDateColumn.Read())
+		feedInfo.EndDate = parseDate("feed_end_date", endDateColumn.Read())
 	}
-	return transfers
+	return feedInfo, w
 }
 
 func parseInt32(s string) *int32 {
@@ -574,7 +1383,7 @@ func parseInt32(s string) *int32 {
 	return &i32
 }
 
-func parseCalendar(f *csv.File, m map[string]Service, timezone *time.Location) {
+func parseCalendar(f *csv.File, m map[string]Service, timezone *time.Location) []warnings.StaticWarning {
 	startDateColumn := f.RequiredColumn("start_date")
 	endDateColumn := f.RequiredColumn("end_date")
 	serviceIDColumn := f.RequiredColumn("service_id")
@@ -591,21 +1400,27 @@ func parseCalendar(f *csv.File, m map[string]Service, timezone *time.Location) {
 		dayColumns[i] = f.RequiredColumn(days)
 	}
 
-	if err := f.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return
+	if warnings := checkForMissingColumns(f); len(warnings) > 0 {
+		return warnings
 	}
 
 	parseBool := func(s string) bool {
 		return s == "1"
 	}
+	var w []warnings.StaticWarning
 	for f.NextRow() {
+		if missingKeys := f.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(f, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
 		startDate, err := parseTime(startDateColumn.Read(), timezone)
 		if err != nil {
+			w = append(w, warnings.NewStaticWarning(f, warnings.InvalidValue{Column: "start_date", Value: startDateColumn.Read()}))
 			continue
 		}
 		endDate, err := parseTime(endDateColumn.Read(), timezone)
 		if err != nil {
+			w = append(w, warnings.NewStaticWarning(f, warnings.InvalidValue{Column: "end_date", Value: endDateColumn.Read()}))
 			continue
 		}
 		service := Service{
@@ -620,33 +1435,35 @@ func parseCalendar(f *csv.File, m map[string]Service, timezone *time.Location) {
 			StartDate: startDate,
 			EndDate:   endDate,
 		}
-		if missingKeys := f.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping calendar because of missing keys %s", missingKeys)
-			continue
-		}
 		m[service.Id] = service
 	}
+	return w
 }
 
-func parseCalendarDates(csv *csv.File, m map[string]Service, timezone *time.Location) {
+func parseCalendarDates(csv *csv.File, m map[string]Service, timezone *time.Location) []warnings.StaticWarning {
 	serviceIDColumn := csv.RequiredColumn("service_id")
 	dateColumn := csv.RequiredColumn("date")
 	exceptionTypeColumn := csv.RequiredColumn("exception_type")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return warnings
 	}
 
+	var w []warnings.StaticWarning
 	for csv.NextRow() {
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
 		serviceId := serviceIDColumn.Read()
 		date, err := parseTime(dateColumn.Read(), timezone)
 		if err != nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "date", Value: dateColumn.Read()}))
 			continue
 		}
 		exceptionType := exceptionTypeColumn.Read()
-		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping calendar because of missing keys %s", missingKeys)
+		if exceptionType != "1" && exceptionType != "2" {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "exception_type", Value: exceptionType}))
 			continue
 		}
 		service, ok := m[serviceId]
@@ -667,18 +1484,18 @@ func parseCalendarDates(csv *csv.File, m map[string]Service, timezone *time.Loca
 			service.AddedDates = append(service.AddedDates, date)
 		case "2":
 			service.RemovedDates = append(service.RemovedDates, date)
-		default:
-			continue
 		}
 		m[service.Id] = service
 	}
+	return w
 }
 
 func parseTime(s string, timezone *time.Location) (time.Time, error) {
 	return time.ParseInLocation("20060102", s, timezone)
 }
 
-func parseScheduledTrips(csv *csv.File, routes []Route, services []Service, shapeIDToShape map[string]*Shape) []ScheduledTrip {
+func parseScheduledTrips(csv *csv.File, ids *StaticIDs, filter *filterState) ([]ScheduledTrip, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
 	routeIDColumn := csv.RequiredColumn("route_id")
 	serviceIDColumn := csv.RequiredColumn("service_id")
 	tripIDColumn := csv.RequiredColumn("trip_id")
@@ -690,24 +1507,15 @@ func parseScheduledTrips(csv *csv.File, routes []Route, services []Service, shap
 	bikesAllowedColumn := csv.OptionalColumn("bikes_allowed")
 	shapeIDColumn := csv.OptionalColumn("shape_id")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return nil
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
 	}
 
-	idToService := map[string]*Service{}
-	for i := range services {
-		idToService[services[i].Id] = &services[i]
-	}
-	idToRoute := map[string]*Route{}
-	for i := range routes {
-		idToRoute[routes[i].Id] = &routes[i]
-	}
 	var trips []ScheduledTrip
 	for csv.NextRow() {
 		trip := ScheduledTrip{
-			Route:                idToRoute[routeIDColumn.Read()],
-			Service:              idToService[serviceIDColumn.Read()],
+			Route:                ids.routes[routeIDColumn.Read()],
+			Service:              ids.services[serviceIDColumn.Read()],
 			ID:                   tripIDColumn.Read(),
 			Headsign:             tripHeadsignColumn.Read(),
 			ShortName:            tripShortNameColumn.Read(),
@@ -719,32 +1527,40 @@ func parseScheduledTrips(csv *csv.File, routes []Route, services []Service, shap
 
 		shapeIDOrNil := shapeIDColumn.Read()
 		if shapeIDOrNil != "" {
-			if shape, ok := shapeIDToShape[shapeIDOrNil]; ok {
+			if shape, ok := ids.shapes[shapeIDOrNil]; ok {
 				trip.Shape = shape
 			} else {
-				log.Printf("Shape %s not found for trip %s", shapeIDOrNil, trip.ID)
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "shape_id", Value: shapeIDOrNil}))
 			}
 		}
 
 		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping trip because of missing keys %s", missingKeys)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		if !filter.keepTrip(&trip, routeIDColumn.Read()) {
 			continue
 		}
 		if trip.Route == nil {
-			log.Print("Skipping trip because of missing route")
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "route_id", Value: routeIDColumn.Read()}))
 			continue
 		}
 		if trip.Service == nil {
-			log.Print("Skipping trip because of missing service")
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "service_id", Value: serviceIDColumn.Read()}))
 			continue
 		}
 		trips = append(trips, trip)
 	}
-	return trips
+	return trips, w
 }
 
-func parseScheduledStopTimes(csv *csv.File, stops []Stop, trips []ScheduledTrip) {
-	stopIDColumn := csv.RequiredColumn("stop_id")
+// parseScheduledStopTimes parses the stop times and attaches them to their trips.
+//
+// If visit is non-nil, the stop times are instead passed to visit one trip at a time and are not retained.
+// In this case hasNonEmptyShapeDistRow must already say whether any row of the file has a shape distance.
+func parseScheduledStopTimes(csv *csv.File, ids *StaticIDs, trips []ScheduledTrip, parallelism int, hasNonEmptyShapeDistRow, byGeometry bool, filter *filterState, visit func(trip *ScheduledTrip, stopTimes []ScheduledStopTime)) []warnings.StaticWarning {
+	var w []warnings.StaticWarning
+	stopIDColumn := csv.OptionalColumn("stop_id")
 	stopSequenceKey := csv.RequiredColumn("stop_sequence")
 	tripIDColumn := csv.RequiredColumn("trip_id")
 	arrivalTimeColumn := csv.OptionalColumn("arrival_time")
@@ -756,46 +1572,57 @@ func parseScheduledStopTimes(csv *csv.File, stops []Stop, trips []ScheduledTrip)
 	continuousDropOffColumn := csv.OptionalColumn("continuous_drop_off")
 	shapeDistanceTraveledColumn := csv.OptionalColumn("shape_dist_traveled")
 	timepointColumn := csv.OptionalColumn("timepoint")
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return
-	}
-
-	idToStop := map[string]*Stop{}
-	for i := range stops {
-		idToStop[stops[i].Id] = &stops[i]
+	flexFields := newFlexStopTimeFields(csv, ids)
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return warnings
 	}
-	idToTrip := map[string]*ScheduledTrip{}
-	for i := range trips {
-		idToTrip[trips[i].ID] = &trips[i]
+	if !flexFields.hasStopOrLocationColumn {
+		return []warnings.StaticWarning{
+			warnings.NewStaticWarning(csv, warnings.MissingOneOfColumns{Columns: []string{"stop_id", "location_group_id", "location_id"}}),
+		}
 	}
+
+	idToStop := ids.stops
+	idToTrip := ids.trips
 	var currentTrip *ScheduledTrip
 	var currentTripID string
-	var hasNonEmptyShapeDistRow = false
+	// State used when visiting the stop times of each trip.
+	var buffer []ScheduledStopTime
+	visited := map[*ScheduledTrip]bool{}
+	flush := func() {
+		visited[currentTrip] = true
+		visit(currentTrip, processStopTimes(buffer, currentTrip.Shape, hasNonEmptyShapeDistRow, byGeometry))
+		buffer = buffer[:0]
+	}
 	for csv.NextRow() {
 		arrival, arrivalOk := parseGtfsTimeToDuration(arrivalTimeColumn.Read())
 		departure, departureOk := parseGtfsTimeToDuration(departureTimeColumn.Read())
 
 		if !departureOk {
-			arrival = departure
+			departure = arrival
 		}
 		if !arrivalOk {
-			departure = arrival
+			arrival = departure
 		}
 		if len(shapeDistanceTraveledColumn.Read()) > 0 {
 			hasNonEmptyShapeDistRow = true
 		}
 		stopSequence, err := strconv.Atoi(stopSequenceKey.Read())
 		if err != nil {
-			// TODO: log a warning
+			if stopSequenceKey.Read() != "" {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "stop_sequence", Value: stopSequenceKey.Read()}))
+			} else {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: []string{"stop_sequence"}}))
+			}
 			continue
 		}
 		stopTime := ScheduledStopTime{
-			Stop:                  idToStop[stopIDColumn.Read()],
 			Headsign:              stopHeadsignColumn.Read(),
 			ArrivalTime:           arrival,
 			StopSequence:          stopSequence,
 			DepartureTime:         departure,
+			HasArrivalTime:        arrivalOk,
+			HasDepartureTime:      departureOk,
 			PickupType:            parsePickupDropOffPolicy(pickupTypeColumn.ReadOr("")),
 			DropOffType:           parsePickupDropOffPolicy(dropOffTypeColumn.ReadOr("")),
 			ContinuousPickup:      parsePickupDropOffPolicy(continuousPickupColumn.ReadOr("")),
@@ -806,34 +1633,156 @@ func parseScheduledStopTimes(csv *csv.File, stops []Stop, trips []ScheduledTrip)
 		tripID := tripIDColumn.Read()
 		if currentTrip == nil || currentTripID != tripID {
 			thisTrip := idToTrip[tripID]
-			if currentTrip != nil && cap(thisTrip.StopTimes) == 0 {
-				thisTrip.StopTimes = make([]ScheduledStopTime, 0, len(currentTrip.StopTimes))
+			if visit != nil {
+				if currentTrip != nil {
+					flush()
+				}
+				if thisTrip != nil && visited[thisTrip] {
+					if currentTripID != tripID {
+						w = append(w, warnings.NewStaticWarning(csv, warnings.NonContiguousStopTimes{TripID: tripID}))
+					}
+					thisTrip = nil
+				}
+			} else {
+				if currentTrip != nil {
+					filter.endTripStopTimes(currentTrip)
+				}
+				if thisTrip != nil && filter.isOutsideBoundingBox(thisTrip) {
+					if currentTripID != tripID {
+						w = append(w, warnings.NewStaticWarning(csv, warnings.NonContiguousStopTimes{TripID: tripID}))
+					}
+					thisTrip = nil
+				} else if currentTrip != nil && thisTrip != nil && cap(thisTrip.StopTimes) == 0 {
+					thisTrip.StopTimes = make([]ScheduledStopTime, 0, len(currentTrip.StopTimes))
+				}
 			}
 			currentTrip = thisTrip
 			currentTripID = tripID
 		}
 		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping stop time because of missing keys %s", missingKeys)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
 			continue
 		}
-		if stopTime.Stop == nil {
+		if currentTrip == nil {
+			if idToTrip[tripID] == nil && !filter.isRemovedTrip(tripID) {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "trip_id", Value: tripID}))
+			}
 			continue
 		}
-		if currentTrip == nil {
+		if stopID := stopIDColumn.Read(); stopID != "" {
+			stopTime.Stop = idToStop[stopID]
+			if stopTime.Stop == nil {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "stop_id", Value: stopID}))
+				continue
+			}
+		}
+		if kind := flexFields.read(&stopTime); kind != nil {
+			w = append(w, warnings.NewStaticWarning(csv, kind))
 			continue
 		}
-		currentTrip.StopTimes = append(currentTrip.StopTimes, stopTime)
+		stopTime.Trip = currentTrip
+		if visit != nil {
+			buffer = append(buffer, stopTime)
+		} else {
+			currentTrip.StopTimes = append(currentTrip.StopTimes, stopTime)
+			filter.addStopTime(currentTrip, &stopTime)
+		}
 	}
+	if visit != nil {
+		if currentTrip != nil {
+			flush()
+		}
+		for i := range trips {
+			if !visited[&trips[i]] {
+				visit(&trips[i], nil)
+			}
+		}
+		return w
+	}
+	if currentTrip != nil {
+		filter.endTripStopTimes(currentTrip)
+	}
+	var tripsToProcess []*ScheduledTrip
 	for _, trip := range idToTrip {
-		sort.Slice(trip.StopTimes, func(i, j int) bool {
-			return trip.StopTimes[i].StopSequence < trip.StopTimes[j].StopSequence
-		})
-		if hasNonEmptyShapeDistRow {
-			trip.StopTimes = interpolateStopTimesByShapeDist(trip.StopTimes)
-		} else {
-			trip.StopTimes = interpolateStopTimes(trip.StopTimes)
+		tripsToProcess = append(tripsToProcess, trip)
+	}
+	forEachInParallel(len(tripsToProcess), parallelism, func(i int) {
+		trip := tripsToProcess[i]
+		trip.StopTimes = processStopTimes(trip.StopTimes, trip.Shape, hasNonEmptyShapeDistRow, byGeometry)
+	})
+	return w
+}
+
+// processStopTimes sorts the stop times of a trip and interpolates missing times.
+//
+// It is used both when the stop times are stored and when they are visited, so that both give the same result.
+// Missing times are interpolated by shape distance if any row of the feed has a shape distance.
+func processStopTimes(stopTimes []ScheduledStopTime, shape *Shape, byShapeDist, byGeometry bool) []ScheduledStopTime {
+	sort.Slice(stopTimes, func(i, j int) bool {
+		return stopTimes[i].StopSequence < stopTimes[j].StopSequence
+	})
+	if byShapeDist {
+		stopTimes = interpolateStopTimesByShapeDist(stopTimes)
+	} else if byGeometry {
+		stopTimes = interpolateStopTimesByGeometry(stopTimes, shape)
+	} else {
+		stopTimes = interpolateStopTimes(stopTimes)
+	}
+	for i := range stopTimes {
+		// GTFS-Flex stop times have a window instead of arrival and departure times.
+		if stopTimes[i].HasPickupDropOffWindow() {
+			stopTimes[i].ArrivalTime = 0
+			stopTimes[i].DepartureTime = 0
+			stopTimes[i].HasArrivalTime = false
+			stopTimes[i].HasDepartureTime = false
+		}
+	}
+	return stopTimes
+}
+
+// hasShapeDistances returns whether any row of the stop_times.txt file has a shape distance.
+//
+// It reads the file an extra time and returns false if it cannot be read; errors are reported when the file
+// is parsed.
+func hasShapeDistances(fsys fs.FS) bool {
+	f, err := openCsvFile(fsys, constants.StopTimesFile)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	shapeDistanceTraveledColumn := f.OptionalColumn("shape_dist_traveled")
+	for f.NextRow() {
+		if len(shapeDistanceTraveledColumn.Read()) > 0 {
+			return true
 		}
 	}
+	return false
+}
+
+// forEachInParallel calls f for each integer in [0, n), using up to parallelism goroutines.
+func forEachInParallel(n, parallelism int, f func(i int)) {
+	if parallelism <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	chunkSize := (n + parallelism - 1) / parallelism
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunkSize {
+		end := start + chunkSize
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				f(i)
+			}
+		}(start, end)
+	}
+	wg.Wait()
 }
 
 func parseGtfsTimeToDuration(s string) (time.Duration, bool) {
@@ -841,10 +1790,12 @@ func parseGtfsTimeToDuration(s string) (time.Duration, bool) {
 		return 0, false
 	}
 	var pieces [3]int
+	var digits [3]int
 	var i int
 	for _, c := range s {
 		if '0' <= c && c <= '9' {
 			pieces[i] = 10*pieces[i] + int(c-'0')
+			digits[i]++
 		} else if c == ':' {
 			i++
 			if i > 2 {
@@ -856,6 +1807,12 @@ func parseGtfsTimeToDuration(s string) (time.Duration, bool) {
 			return 0, false
 		}
 	}
+	// Every piece must have a digit, so that blank values and values like "::" are rejected.
+	for j := 0; j <= i; j++ {
+		if digits[j] == 0 {
+			return 0, false
+		}
+	}
 	hours := pieces[0]
 	minutes := pieces[1]
 	seconds := pieces[2]
@@ -869,28 +1826,44 @@ type ShapeRow struct {
 	ShapeDistTraveled *float64
 }
 
-func parseShapes(csv *csv.File) []Shape {
+func parseShapes(csv *csv.File) ([]Shape, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
 	shapeIDColumn := csv.RequiredColumn("shape_id")
 	shapePtLatColumn := csv.RequiredColumn("shape_pt_lat")
 	shapePtLonColumn := csv.RequiredColumn("shape_pt_lon")
 	shapePtSequenceColumn := csv.RequiredColumn("shape_pt_sequence")
 	shapeDistTraveled := csv.OptionalColumn("shape_dist_traveled")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return nil
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
 	}
 
 	shapeIDToRowData := map[string][]ShapeRow{}
 	for csv.NextRow() {
 		shapeID := shapeIDColumn.Read()
-		shapePtLat := parseFloat64(shapePtLatColumn.Read())
-		shapePtLon := parseFloat64(shapePtLonColumn.Read())
-		shapePtSequence := parseInt32(shapePtSequenceColumn.Read())
+		rawShapePtLat := shapePtLatColumn.Read()
+		rawShapePtLon := shapePtLonColumn.Read()
+		rawShapePtSequence := shapePtSequenceColumn.Read()
 		shapeDistTraveled := parseFloat64(shapeDistTraveled.Read())
 
 		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping shape because of missing keys %s", missingKeys)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+
+		shapePtLat := parseFloat64(rawShapePtLat)
+		if shapePtLat == nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "shape_pt_lat", Value: rawShapePtLat}))
+			continue
+		}
+		shapePtLon := parseFloat64(rawShapePtLon)
+		if shapePtLon == nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "shape_pt_lon", Value: rawShapePtLon}))
+			continue
+		}
+		shapePtSequence := parseInt32(rawShapePtSequence)
+		if shapePtSequence == nil {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "shape_pt_sequence", Value: rawShapePtSequence}))
 			continue
 		}
 
@@ -929,19 +1902,19 @@ func parseShapes(csv *csv.File) []Shape {
 		return shapes[i].ID < shapes[j].ID
 	})
 
-	return shapes
+	return shapes, w
 }
 
-func parseFrequencies(csv *csv.File, tripIDToScheduledTrip map[string]*ScheduledTrip) {
+func parseFrequencies(csv *csv.File, tripIDToScheduledTrip map[string]*ScheduledTrip) []warnings.StaticWarning {
+	var w []warnings.StaticWarning
 	tripIDColumn := csv.RequiredColumn("trip_id")
 	startTimeColumn := csv.RequiredColumn("start_time")
 	endTimeColumn := csv.RequiredColumn("end_time")
 	headwaySecsColumn := csv.RequiredColumn("headway_secs")
 	exactTimesColumn := csv.OptionalColumn("exact_times")
 
-	if err := csv.MissingRequiredColumns(); err != nil {
-		fmt.Println(err)
-		return
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return warnings
 	}
 
 	for csv.NextRow() {
@@ -951,27 +1924,27 @@ func parseFrequencies(csv *csv.File, tripIDToScheduledTrip map[string]*Scheduled
 		headwaySecs := headwaySecsColumn.Read()
 
 		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
-			log.Printf("Skipping frequency because of missing keys %s", missingKeys)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
 			continue
 		}
 		scheduledTripOrNil := tripIDToScheduledTrip[tripID]
 		if scheduledTripOrNil == nil {
-			log.Printf("Skipping frequency because of missing trip %s", tripID)
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidReference{Column: "trip_id", Value: tripID}))
 			continue
 		}
 		headwaySecsOrNil := parseInt32(headwaySecs)
 		if headwaySecsOrNil == nil {
-			log.Print("Skipping frequency because of invalid headway_secs")
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "headway_secs", Value: headwaySecs}))
 			continue
 		}
 		startTimeDuration, startTimeDurationOk := parseGtfsTimeToDuration(startTime)
 		if !startTimeDurationOk {
-			log.Print("Skipping frequency because of invalid start_time")
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "start_time", Value: startTime}))
 			continue
 		}
 		endTimeDuration, endTimeDurationOk := parseGtfsTimeToDuration(endTime)
 		if !endTimeDurationOk {
-			log.Print("Skipping frequency because of invalid end_time")
+			w = append(w, warnings.NewStaticWarning(csv, warnings.InvalidValue{Column: "end_time", Value: endTime}))
 			continue
 		}
 
@@ -984,6 +1957,7 @@ func parseFrequencies(csv *csv.File, tripIDToScheduledTrip map[string]*Scheduled
 
 		scheduledTripOrNil.Frequencies = append(scheduledTripOrNil.Frequencies, frequency)
 	}
+	return w
 }
 
 func checkForMissingColumns(csv *csv.File) []warnings.StaticWarning {
@@ -3,14 +3,21 @@ package gtfs
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/OneBusAway/go-gtfs/constants"
 	"github.com/OneBusAway/go-gtfs/warnings"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 var (
@@ -19,6 +26,10 @@ var (
 )
 
 func TestParse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %s", err)
+	}
 	defaultAgency := Agency{
 		Id:       "a",
 		Name:     "b",
@@ -29,7 +40,7 @@ func TestParse(t *testing.T) {
 		Id:       "e",
 		Name:     "f",
 		Url:      "g",
-		Timezone: "h",
+		Timezone: "d",
 	}
 	defaultRoute := Route{
 		Id:                "route_id",
@@ -146,6 +157,89 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "agency without ID",
+			content: newZipBuilder().add(
+				"agency.txt",
+				"agency_name,agency_url,agency_timezone\nb,c,d",
+			).build(),
+			expected: &Static{
+				Agencies: []Agency{
+					{
+						Id:       "b_id",
+						Name:     "b",
+						Url:      "c",
+						Timezone: "d",
+					},
+				},
+			},
+		},
+		{
+			desc: "agency without ID with default agency ID",
+			content: newZipBuilder().add(
+				"agency.txt",
+				"agency_id,agency_name,agency_url,agency_timezone\n,b,c,d",
+			).build(),
+			opts: ParseStaticOptions{DefaultAgencyID: "a"},
+			expected: &Static{
+				Agencies: []Agency{defaultAgency},
+			},
+		},
+		{
+			desc: "agencies without ID with default agency ID",
+			content: newZipBuilder().add(
+				"agency.txt",
+				"agency_id,agency_name,agency_url,agency_timezone\n,b,c,d\n,f,g,d",
+			).build(),
+			opts: ParseStaticOptions{DefaultAgencyID: "a"},
+			expected: &Static{
+				Agencies: []Agency{
+					{
+						Id:       "b_id",
+						Name:     "b",
+						Url:      "c",
+						Timezone: "d",
+					},
+					{
+						Id:       "f_id",
+						Name:     "f",
+						Url:      "g",
+						Timezone: "d",
+					},
+				},
+			},
+		},
+		{
+			desc: "agencies with different timezones",
+			content: newZipBuilder().add(
+				"agency.txt",
+				"agency_id,agency_name,agency_url,agency_timezone\na,b,c,d\ne,f,g,America/New_York",
+			).build(),
+			expected: &Static{
+				Agencies: []Agency{
+					defaultAgency,
+					{
+						Id:       "e",
+						Name:     "f",
+						Url:      "g",
+						Timezone: "America/New_York",
+					},
+				},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind: warnings.AgencyTimezoneMismatch{
+							AgencyID:         "e",
+							Timezone:         "America/New_York",
+							ExpectedTimezone: "d",
+						},
+						File:          constants.AgencyFile,
+						RowNumber:     2,
+						RowContent:    []string{"e", "f", "g", "America/New_York"},
+						HeaderContent: []string{"agency_id", "agency_name", "agency_url", "agency_timezone"},
+					},
+				},
+			},
+		},
 		{
 			desc: "route with only required fields",
 			content: newZipBuilder().add(
@@ -205,7 +299,7 @@ func TestParse(t *testing.T) {
 			desc: "route with matching specified agency",
 			content: newZipBuilder().add(
 				"agency.txt",
-				"agency_id,agency_name,agency_url,agency_timezone\na,b,c,d\ne,f,g,h",
+				"agency_id,agency_name,agency_url,agency_timezone\na,b,c,d\ne,f,g,d",
 			).add(
 				"routes.txt",
 				"route_id,route_type,agency_id\na,3,e",
@@ -321,6 +415,15 @@ func TestParse(t *testing.T) {
 			).build(),
 			expected: &Static{
 				Stops: []Stop{{Id: "a"}},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.SameStopTransfer{StopID: "a"},
+						File:          constants.TransfersFile,
+						RowNumber:     1,
+						RowContent:    []string{"a", "a"},
+						HeaderContent: []string{"from_stop_id", "to_stop_id"},
+					},
+				},
 			},
 		},
 		{
@@ -334,6 +437,15 @@ func TestParse(t *testing.T) {
 			).build(),
 			expected: &Static{
 				Stops: []Stop{{Id: "a"}},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.InvalidReference{Column: "to_stop_id", Value: "b"},
+						File:          constants.TransfersFile,
+						RowNumber:     1,
+						RowContent:    []string{"a", "b"},
+						HeaderContent: []string{"from_stop_id", "to_stop_id"},
+					},
+				},
 			},
 		},
 		{
@@ -347,6 +459,15 @@ func TestParse(t *testing.T) {
 			).build(),
 			expected: &Static{
 				Stops: []Stop{{Id: "b"}},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.InvalidReference{Column: "from_stop_id", Value: "a"},
+						File:          constants.TransfersFile,
+						RowNumber:     1,
+						RowContent:    []string{"a", "b"},
+						HeaderContent: []string{"from_stop_id", "to_stop_id"},
+					},
+				},
 			},
 		},
 		{
@@ -391,6 +512,74 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "feed_info.txt with only required fields",
+			content: newZipBuilder().add(
+				"feed_info.txt",
+				"feed_publisher_name,feed_publisher_url,feed_lang\na,b,en",
+			).build(),
+			expected: &Static{
+				FeedInfo: &FeedInfo{
+					PublisherName: "a",
+					PublisherUrl:  "b",
+					Language:      "en",
+				},
+			},
+		},
+		{
+			desc: "feed_info.txt with all fields",
+			content: newZipBuilder().add(
+				"agency.txt",
+				"agency_id,agency_name,agency_url,agency_timezone\na,b,c,America/New_York",
+			).add(
+				"feed_info.txt",
+				"feed_publisher_name,feed_publisher_url,feed_lang,default_lang,feed_start_date,feed_end_date,"+
+					"feed_version,feed_contact_email,feed_contact_url\n"+
+					"a,b,mul,en,20220504,20220507,v1,c,d",
+			).build(),
+			expected: &Static{
+				Agencies: []Agency{
+					{
+						Id:       "a",
+						Name:     "b",
+						Url:      "c",
+						Timezone: "America/New_York",
+					},
+				},
+				FeedInfo: &FeedInfo{
+					PublisherName:   "a",
+					PublisherUrl:    "b",
+					Language:        "mul",
+					DefaultLanguage: "en",
+					StartDate:       time.Date(2022, 5, 4, 0, 0, 0, 0, newYork),
+					EndDate:         time.Date(2022, 5, 7, 0, 0, 0, 0, newYork),
+					Version:         "v1",
+					ContactEmail:    "c",
+					ContactUrl:      "d",
+				},
+			},
+		},
+		{
+			desc: "feed_info.txt with timezone override",
+			content: newZipBuilder().add(
+				"feed_info.txt",
+				"feed_publisher_name,feed_publisher_url,feed_lang,feed_start_date",
+				"a,b,en,20220504",
+			).add(
+				"agency.txt",
+				"agency_id,agency_name,agency_url,agency_timezone\na,b,c,d",
+			).build(),
+			opts: ParseStaticOptions{Timezone: newYork},
+			expected: &Static{
+				Agencies: []Agency{defaultAgency},
+				FeedInfo: &FeedInfo{
+					PublisherName: "a",
+					PublisherUrl:  "b",
+					Language:      "en",
+					StartDate:     time.Date(2022, 5, 4, 0, 0, 0, 0, newYork),
+				},
+			},
+		},
 		{
 			desc: "trip",
 			content: newZipBuilder().add(
@@ -438,6 +627,8 @@ func TestParse(t *testing.T) {
 								StopSequence:          50,
 								ArrivalTime:           4*time.Hour + 5*time.Minute + 6*time.Second,
 								DepartureTime:         13*time.Hour + 14*time.Minute + 15*time.Second,
+								HasArrivalTime:        true,
+								HasDepartureTime:      true,
 								PickupType:            PickupDropOffPolicy_Yes,
 								DropOffType:           PickupDropOffPolicy_No,
 								ContinuousPickup:      PickupDropOffPolicy_PhoneAgency,
@@ -504,6 +695,45 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "shapes with invalid values",
+			content: newZipBuilderWithDefaults().add(
+				"shapes.txt",
+				"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+				"shape_id,north,2,3",
+				"shape_id,1,2,third",
+				"shape_id,1,2,1",
+			).build(),
+			expected: &Static{
+				Agencies: []Agency{defaultAgency},
+				Routes:   []Route{defaultRoute},
+				Services: []Service{defaultService},
+				Stops:    []Stop{defaultStop},
+				Trips:    []ScheduledTrip{defaultTrip},
+				Shapes: []Shape{
+					{
+						ID:     "shape_id",
+						Points: []ShapePoint{{Latitude: 1, Longitude: 2}},
+					},
+				},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.InvalidValue{Column: "shape_pt_lat", Value: "north"},
+						File:          constants.ShapesFile,
+						RowNumber:     1,
+						RowContent:    []string{"shape_id", "north", "2", "3"},
+						HeaderContent: []string{"shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence"},
+					},
+					{
+						Kind:          warnings.InvalidValue{Column: "shape_pt_sequence", Value: "third"},
+						File:          constants.ShapesFile,
+						RowNumber:     2,
+						RowContent:    []string{"shape_id", "1", "2", "third"},
+						HeaderContent: []string{"shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence"},
+					},
+				},
+			},
+		},
 		{
 			desc: "empty shapes file",
 			content: newZipBuilderWithDefaults().add(
@@ -527,6 +757,15 @@ func TestParse(t *testing.T) {
 					},
 				},
 				Shapes: []Shape{},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.InvalidReference{Column: "shape_id", Value: "shape_id"},
+						File:          constants.TripsFile,
+						RowNumber:     1,
+						RowContent:    []string{"route_id", "service_id", "trip_id", "shape_id"},
+						HeaderContent: []string{"route_id", "service_id", "trip_id", "shape_id"},
+					},
+				},
 			},
 		},
 		{
@@ -1011,6 +1250,112 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "stop times with only one of arrival and departure time",
+			content: newZipBuilderWithDefaults().add(
+				"stop_times.txt",
+				"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+				"trip_id,stop_id,1,08:00:00,",
+				"trip_id,stop_id,2,,09:00:00",
+			).build(),
+			expected: &Static{
+				Agencies: []Agency{defaultAgency},
+				Routes:   []Route{defaultRoute},
+				Services: []Service{defaultService},
+				Stops:    []Stop{defaultStop},
+				Trips: []ScheduledTrip{
+					{
+						ID:      defaultTrip.ID,
+						Route:   &defaultRoute,
+						Service: &defaultService,
+						StopTimes: []ScheduledStopTime{
+							{
+								Stop:              &defaultStop,
+								StopSequence:      1,
+								ArrivalTime:       8 * time.Hour,
+								DepartureTime:     8 * time.Hour,
+								HasArrivalTime:    true,
+								ExactTimes:        true,
+								PickupType:        PickupDropOffPolicy_No,
+								DropOffType:       PickupDropOffPolicy_No,
+								ContinuousPickup:  PickupDropOffPolicy_No,
+								ContinuousDropOff: PickupDropOffPolicy_No,
+							},
+							{
+								Stop:              &defaultStop,
+								StopSequence:      2,
+								ArrivalTime:       9 * time.Hour,
+								DepartureTime:     9 * time.Hour,
+								HasDepartureTime:  true,
+								ExactTimes:        true,
+								PickupType:        PickupDropOffPolicy_No,
+								DropOffType:       PickupDropOffPolicy_No,
+								ContinuousPickup:  PickupDropOffPolicy_No,
+								ContinuousDropOff: PickupDropOffPolicy_No,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "stop times with invalid values",
+			content: newZipBuilderWithDefaults().add(
+				"stop_times.txt",
+				"trip_id,stop_id,stop_sequence",
+				"trip_id,stop_id,1",
+				"unknown_trip,stop_id,2",
+				"trip_id,unknown_stop,3",
+				"trip_id,stop_id,four",
+			).build(),
+			expected: &Static{
+				Agencies: []Agency{defaultAgency},
+				Routes:   []Route{defaultRoute},
+				Services: []Service{defaultService},
+				Stops:    []Stop{defaultStop},
+				Trips: []ScheduledTrip{
+					{
+						ID:      defaultTrip.ID,
+						Route:   &defaultRoute,
+						Service: &defaultService,
+						StopTimes: []ScheduledStopTime{
+							{
+								Stop:              &defaultStop,
+								StopSequence:      1,
+								ExactTimes:        true,
+								PickupType:        PickupDropOffPolicy_No,
+								DropOffType:       PickupDropOffPolicy_No,
+								ContinuousPickup:  PickupDropOffPolicy_No,
+								ContinuousDropOff: PickupDropOffPolicy_No,
+							},
+						},
+					},
+				},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.InvalidReference{Column: "trip_id", Value: "unknown_trip"},
+						File:          constants.StopTimesFile,
+						RowNumber:     2,
+						RowContent:    []string{"unknown_trip", "stop_id", "2"},
+						HeaderContent: []string{"trip_id", "stop_id", "stop_sequence"},
+					},
+					{
+						Kind:          warnings.InvalidReference{Column: "stop_id", Value: "unknown_stop"},
+						File:          constants.StopTimesFile,
+						RowNumber:     3,
+						RowContent:    []string{"trip_id", "unknown_stop", "3"},
+						HeaderContent: []string{"trip_id", "stop_id", "stop_sequence"},
+					},
+					{
+						Kind:          warnings.InvalidValue{Column: "stop_sequence", Value: "four"},
+						File:          constants.StopTimesFile,
+						RowNumber:     4,
+						RowContent:    []string{"trip_id", "stop_id", "four"},
+						HeaderContent: []string{"trip_id", "stop_id", "stop_sequence"},
+					},
+				},
+			},
+		},
 		{
 			desc: "frequencies with missing trip",
 			content: newZipBuilderWithDefaults().add(
@@ -1024,6 +1369,15 @@ func TestParse(t *testing.T) {
 				Services: []Service{defaultService},
 				Stops:    []Stop{defaultStop},
 				Trips:    []ScheduledTrip{defaultTrip},
+				Warnings: []warnings.StaticWarning{
+					{
+						Kind:          warnings.InvalidReference{Column: "trip_id", Value: "some_trip"},
+						File:          constants.FrequenciesFile,
+						RowNumber:     1,
+						RowContent:    []string{"some_trip", "00:00:00", "01:00:00", "180"},
+						HeaderContent: []string{"trip_id", "start_time", "end_time", "headway_secs"},
+					},
+				},
 			},
 		},
 		{
@@ -1173,6 +1527,7 @@ func TestParse(t *testing.T) {
 			if err != nil {
 				t.Errorf("error when parsing: %s", err)
 			}
+			linkStopTimes(tc.expected.Trips)
 			if diff := cmp.Diff(actual, tc.expected); diff != "" {
 				t.Errorf("not the same: \ngot: %+v != \nwant:%+v\ndiff:%s", actual, tc.expected, diff)
 			}
@@ -1180,72 +1535,1288 @@ func TestParse(t *testing.T) {
 	}
 }
 
-type zipBuilder struct {
-	m map[string]string
-}
-
-func newZipBuilder() *zipBuilder {
-	return (&zipBuilder{m: map[string]string{}}).add(
-		"agency.txt", "agency_id,agency_name,agency_url,agency_timezone",
-	).add(
-		"routes.txt", "route_id,route_type",
-	).add(
-		"stops.txt", "stop_id",
-	).add(
-		"transfers.txt", "from_stop_id,to_stop_id",
-	).add(
-		"trips.txt", "route_id,service_id,trip_id",
-	).add(
-		"stop_times.txt", "stop_id,trip_id,stop_sequence",
-	)
-}
-
-func newZipBuilderWithDefaults() *zipBuilder {
-	return newZipBuilder().add(
-		"agency.txt",
-		"agency_id,agency_name,agency_url,agency_timezone\na,b,c,d",
-	).add(
-		"routes.txt",
-		"route_id,route_type\nroute_id,3",
-	).add(
+func TestParseTransfers(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
 		"stops.txt",
-		"stop_id\nstop_id",
-	).add(
-		"calendar.txt",
-		"service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n"+
-			"service_id,0,0,0,0,0,0,0,20220504,20220507",
-	).add(
-		"stop_times.txt",
-		"stop_id,trip_id,arrival_time,departure_time,stop_sequence,stop_headsign\n"+
-			"stop_id,a,04:05:06,13:14:15,50,b",
+		"stop_id\na\nb",
 	).add(
 		"trips.txt",
-		"route_id,service_id,trip_id\nroute_id,service_id,trip_id")
-}
-
-func (z *zipBuilder) add(fileName string, fileContent ...string) *zipBuilder {
-	z.m[fileName] = strings.Join(fileContent, "\n")
-	return z
-}
+		"route_id,service_id,trip_id,block_id",
+		"route_id,service_id,trip_1,block",
+		"route_id,service_id,trip_2,block",
+	).add(
+		"transfers.txt",
+		"from_stop_id,to_stop_id,from_route_id,to_route_id,from_trip_id,to_trip_id,transfer_type,min_transfer_time",
+		"a,b,route_id,route_id,,,2,120",
+		"b,b,,,trip_1,trip_2,4,",
+		",,,,trip_2,trip_1,5,",
+		"a,a,,,,,1,",
+		"a,b,unknown,,,,0,",
+		"a,b,,,,unknown,0,",
+		",,,,trip_1,,4,",
+		",b,,,,,1,",
+		",,route_id,route_id,,,0,",
+		",,,,trip_1,trip_2,,",
+	).build()
 
-func (z *zipBuilder) build() []byte {
-	var b bytes.Buffer
-	zipWriter := zip.NewWriter(&b)
-	for fileName, fileContent := range z.m {
-		fileWriter, err := zipWriter.Create(fileName)
-		if err != nil {
-			panic(err)
-		}
-		if _, err := io.Copy(fileWriter, bytes.NewBufferString(fileContent)); err != nil {
-			panic(err)
-		}
-	}
-	if err := zipWriter.Close(); err != nil {
-		panic(err)
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
 	}
-	return b.Bytes()
-}
 
-func ptr[T any](t T) *T {
-	return &t
+	if diff := cmp.Diff(static.Transfers, []Transfer{
+		{
+			From:            &static.Stops[0],
+			To:              &static.Stops[1],
+			FromRoute:       &static.Routes[0],
+			ToRoute:         &static.Routes[0],
+			Type:            TransferType_RequiresTime,
+			MinTransferTime: ptr(int32(120)),
+		},
+		{
+			From:     &static.Stops[1],
+			To:       &static.Stops[1],
+			FromTrip: &static.Trips[0],
+			ToTrip:   &static.Trips[1],
+			Type:     TransferType_InSeat,
+		},
+		{
+			FromTrip: &static.Trips[1],
+			ToTrip:   &static.Trips[0],
+			Type:     TransferType_InSeatNotAllowed,
+		},
+		{
+			FromRoute: &static.Routes[0],
+			ToRoute:   &static.Routes[0],
+			Type:      TransferType_Recommended,
+		},
+		{
+			FromTrip: &static.Trips[0],
+			ToTrip:   &static.Trips[1],
+			Type:     TransferType_Recommended,
+		},
+	}); diff != "" {
+		t.Errorf("transfers not the same: %s", diff)
+	}
+}
+
+func TestParseStaticReaderAndFile(t *testing.T) {
+	content := newZipBuilderWithDefaults().build()
+	expected, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	fromReader, err := ParseStaticReader(bytes.NewReader(content), int64(len(content)), ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing from reader: %s", err)
+	}
+	if diff := cmp.Diff(fromReader, expected); diff != "" {
+		t.Errorf("not the same when parsing from reader: %s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), "gtfs.zip")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+	fromFile, err := ParseStaticFile(path, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing from file: %s", err)
+	}
+	if diff := cmp.Diff(fromFile, expected); diff != "" {
+		t.Errorf("not the same when parsing from file: %s", diff)
+	}
+
+	if _, err := ParseStaticFile(filepath.Join(t.TempDir(), "missing.zip"), ParseStaticOptions{}); err == nil {
+		t.Errorf("expected an error when parsing a missing file")
+	}
+}
+
+func TestParseStaticFS(t *testing.T) {
+	files := newZipBuilderWithDefaults().m
+	expected, err := ParseStatic(newZipBuilderWithDefaults().build(), ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	mapFS := fstest.MapFS{}
+	nested := &zipBuilder{m: map[string]string{
+		"__MACOSX/feed/._agency.txt": "",
+	}}
+	dir := t.TempDir()
+	for name, content := range files {
+		mapFS[name] = &fstest.MapFile{Data: []byte(content)}
+		nested.m["feed/"+name] = content
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		desc  string
+		parse func() (*Static, error)
+	}{
+		{
+			desc: "map file system",
+			parse: func() (*Static, error) {
+				return ParseStaticFS(mapFS, ParseStaticOptions{})
+			},
+		},
+		{
+			desc: "directory",
+			parse: func() (*Static, error) {
+				return ParseStaticFile(dir, ParseStaticOptions{})
+			},
+		},
+		{
+			desc: "zip with files in a top-level folder",
+			parse: func() (*Static, error) {
+				return ParseStatic(nested.build(), ParseStaticOptions{})
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, err := tc.parse()
+			if err != nil {
+				t.Fatalf("error when parsing: %s", err)
+			}
+			if diff := cmp.Diff(actual, expected); diff != "" {
+				t.Errorf("not the same: %s", diff)
+			}
+		})
+	}
+
+	delete(mapFS, "agency.txt")
+	if _, err := ParseStaticFS(mapFS, ParseStaticOptions{}); err == nil {
+		t.Errorf("expected an error when agency.txt is missing")
+	}
+}
+
+func TestParseStatic_Parallelism(t *testing.T) {
+	content := newZipBuilder().add(
+		"agency.txt",
+		"agency_id,agency_name,agency_url,agency_timezone",
+		"agency,Agency,https://example.com,America/New_York",
+	).add(
+		"feed_info.txt",
+		"feed_publisher_name,feed_publisher_url,feed_lang,feed_start_date",
+		"Publisher,https://example.com,en,20220501",
+	).add(
+		"routes.txt",
+		"route_id,route_type",
+		"route_1,3",
+		"route_2,1",
+	).add(
+		"levels.txt",
+		"level_id,level_index",
+		"level,0",
+	).add(
+		"stops.txt",
+		"stop_id,parent_station,location_type,level_id,zone_id",
+		"station,,1,,",
+		"stop_1,station,0,level,zone_1",
+		"stop_2,,0,,zone_2",
+	).add(
+		"pathways.txt",
+		"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional",
+		"pathway,station,stop_1,1,1",
+	).add(
+		"calendar.txt",
+		"service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date",
+		"weekday,1,1,1,1,1,0,0,20220501,20220531",
+		"weekend,0,0,0,0,0,1,1,20220501,20220531",
+	).add(
+		"calendar_dates.txt",
+		"service_id,date,exception_type",
+		"holiday,20220530,1",
+		"weekday,20220530,2",
+	).add(
+		"shapes.txt",
+		"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+		"shape,1,2,1",
+		"shape,3,4,2",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id,shape_id",
+		"route_1,weekday,trip_1,shape",
+		"route_2,weekend,trip_2,",
+		"route_2,holiday,trip_3,",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_1,stop_1,1,08:00:00,08:00:00",
+		"trip_1,stop_2,2,,",
+		"trip_1,stop_1,3,08:20:00,08:20:00",
+		"trip_2,stop_2,1,09:00:00,09:00:00",
+		"trip_2,stop_1,2,09:30:00,09:30:00",
+		"trip_3,stop_1,1,10:00:00,10:00:00",
+	).add(
+		"frequencies.txt",
+		"trip_id,start_time,end_time,headway_secs",
+		"trip_2,06:00:00,22:00:00,600",
+	).add(
+		"transfers.txt",
+		"from_stop_id,to_stop_id,from_trip_id,to_trip_id,transfer_type",
+		"stop_1,stop_2,,,0",
+		",,trip_1,trip_2,4",
+	).add(
+		"attributions.txt",
+		"organization_name,route_id,is_producer",
+		"Producer,route_1,1",
+	).add(
+		"fare_attributes.txt",
+		"fare_id,price,currency_type,payment_method,transfers",
+		"fare,2.75,USD,0,",
+	).add(
+		"fare_rules.txt",
+		"fare_id,route_id,origin_id",
+		"fare,route_1,zone_1",
+	).add(
+		"areas.txt",
+		"area_id",
+		"area",
+	).add(
+		"stop_areas.txt",
+		"area_id,stop_id",
+		"area,stop_1",
+	).add(
+		"fare_products.txt",
+		"fare_product_id,amount,currency",
+		"product,2.75,USD",
+	).add(
+		"fare_leg_rules.txt",
+		"leg_group_id,from_area_id,fare_product_id",
+		"group,area,product",
+	).add(
+		"timeframes.txt",
+		"timeframe_group_id",
+		"peak",
+	).add(
+		"translations.txt",
+		"table_name,field_name,language,translation,record_id",
+		"stops,stop_name,fr,Arrêt,stop_1",
+	).build()
+
+	expected, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing sequentially: %s", err)
+	}
+	if len(expected.Warnings) == 0 {
+		t.Fatalf("expected the feed to produce warnings")
+	}
+	for _, parallelism := range []int{2, 4, 16} {
+		for i := 0; i < 5; i++ {
+			actual, err := ParseStatic(content, ParseStaticOptions{Parallelism: parallelism})
+			if err != nil {
+				t.Fatalf("error when parsing with parallelism %d: %s", parallelism, err)
+			}
+			if diff := cmp.Diff(actual, expected); diff != "" {
+				t.Errorf("not the same with parallelism %d: %s", parallelism, diff)
+			}
+		}
+	}
+
+	missingTrips := newZipBuilderWithDefaults()
+	delete(missingTrips.m, "trips.txt")
+	_, err = ParseStatic(missingTrips.build(), ParseStaticOptions{Parallelism: 4})
+	if err == nil || !strings.Contains(err.Error(), "trips.txt") {
+		t.Errorf("expected an error for the missing trips.txt file, got %v", err)
+	}
+}
+
+func TestCheckTableDependencies(t *testing.T) {
+	valid := []staticTable{
+		{File: constants.StopsFile},
+		{File: constants.TripsFile},
+		{File: constants.StopTimesFile, DependsOn: []constants.StaticFile{constants.StopsFile, constants.TripsFile}},
+	}
+	if err := checkTableDependencies(valid); err != nil {
+		t.Errorf("error for valid tables: %s", err)
+	}
+	invalid := []staticTable{
+		{File: constants.StopTimesFile, DependsOn: []constants.StaticFile{constants.TripsFile}},
+		{File: constants.TripsFile},
+	}
+	for _, parallelism := range []int{1, 4} {
+		if _, err := runStaticTables(nil, invalid, parallelism); err == nil {
+			t.Errorf("no error for a table depending on a later table with parallelism %d", parallelism)
+		}
+	}
+}
+
+func TestParseStatic_SkipFiles(t *testing.T) {
+	newContent := func() *zipBuilder {
+		return newZipBuilderWithDefaults().add(
+			"shapes.txt",
+			"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+			"shape,1,2,1",
+		).add(
+			"trips.txt",
+			"route_id,service_id,trip_id,shape_id",
+			"route_id,service_id,trip_id,shape",
+		).add(
+			"stop_times.txt",
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+			"trip_id,stop_id,1,08:00:00,08:00:00",
+		)
+	}
+
+	t.Run("skip stop times and shapes", func(t *testing.T) {
+		withoutStopTimes := newContent()
+		delete(withoutStopTimes.m, "stop_times.txt")
+		actual, err := ParseStatic(withoutStopTimes.build(), ParseStaticOptions{
+			SkipFiles: []constants.StaticFile{constants.StopTimesFile, constants.ShapesFile},
+		})
+		if err != nil {
+			t.Fatalf("error when parsing: %s", err)
+		}
+		if len(actual.Trips) != 1 {
+			t.Fatalf("got %d trips, want 1", len(actual.Trips))
+		}
+		if actual.Trips[0].StopTimes != nil {
+			t.Errorf("got stop times %v, want nil", actual.Trips[0].StopTimes)
+		}
+		if actual.Trips[0].Shape != nil || actual.Shapes != nil {
+			t.Errorf("got shapes, want nil")
+		}
+		if len(actual.Services) != 1 {
+			t.Errorf("got %d services, want 1", len(actual.Services))
+		}
+	})
+
+	t.Run("topology only", func(t *testing.T) {
+		content := newContent()
+		delete(content.m, "trips.txt")
+		actual, err := ParseStatic(content.build(), ParseStaticOptions{TopologyOnly: true})
+		if err != nil {
+			t.Fatalf("error when parsing: %s", err)
+		}
+		if len(actual.Agencies) != 1 || len(actual.Routes) != 1 || len(actual.Stops) != 1 {
+			t.Errorf("got %d agencies, %d routes and %d stops, want 1 of each",
+				len(actual.Agencies), len(actual.Routes), len(actual.Stops))
+		}
+		if actual.Trips != nil || actual.Services != nil || actual.Shapes != nil {
+			t.Errorf("got trips, services or shapes, want nil")
+		}
+	})
+
+	t.Run("strict with references to skipped files", func(t *testing.T) {
+		for _, opts := range []ParseStaticOptions{
+			{Strict: true, SkipFiles: []constants.StaticFile{constants.ShapesFile}},
+			{Strict: true, TopologyOnly: true},
+		} {
+			content := newContent().add(
+				"transfers.txt",
+				"from_stop_id,to_stop_id,from_trip_id,to_trip_id,transfer_type",
+				"stop_id,stop_id,trip_id,trip_id,1",
+			)
+			actual, err := ParseStatic(content.build(), opts)
+			if err != nil {
+				t.Fatalf("error when parsing with skipped files %v and topology only %t: %s", opts.SkipFiles, opts.TopologyOnly, err)
+			}
+			if len(actual.Warnings) != 0 {
+				t.Errorf("got warnings %v, want none", actual.Warnings)
+			}
+		}
+	})
+}
+
+func TestParseStatic_Filter(t *testing.T) {
+	content := newZipBuilder().add(
+		"agency.txt",
+		"agency_id,agency_name,agency_url,agency_timezone",
+		"agency_1,b,c,d",
+		"agency_2,b,c,d",
+	).add(
+		"routes.txt",
+		"route_id,agency_id,route_type",
+		"route_1,agency_1,3",
+		"route_2,agency_1,3",
+		"route_3,agency_2,3",
+	).add(
+		"stops.txt",
+		"stop_id,stop_lat,stop_lon,location_type,parent_station",
+		"station_1,1,1,1,",
+		"platform_1,,,0,station_1",
+		"entrance_1,1,1,2,station_1",
+		"stop_2,2,2,0,",
+		"stop_3,3,3,0,",
+	).add(
+		"pathways.txt",
+		"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional",
+		"pathway_1,entrance_1,platform_1,1,1",
+	).add(
+		"transfers.txt",
+		"from_stop_id,to_stop_id,transfer_type",
+		"platform_1,stop_2,0",
+		"stop_2,stop_3,0",
+	).add(
+		"calendar.txt",
+		"service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date",
+		"may,1,1,1,1,1,1,1,20220501,20220531",
+		"june,1,1,1,1,1,1,1,20220601,20220630",
+	).add(
+		"shapes.txt",
+		"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+		"shape_1,1,1,1",
+		"shape_3,3,3,1",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id,shape_id",
+		"route_1,may,trip_1,shape_1",
+		"route_2,june,trip_2,",
+		"route_3,may,trip_3,shape_3",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_1,platform_1,1,08:00:00,08:00:00",
+		"trip_1,stop_2,2,08:10:00,08:10:00",
+		"trip_2,stop_2,1,08:00:00,08:00:00",
+		"trip_3,stop_3,1,08:00:00,08:00:00",
+	).add(
+		"frequencies.txt",
+		"trip_id,start_time,end_time,headway_secs",
+		"trip_3,08:00:00,09:00:00,600",
+	).build()
+
+	type summary struct {
+		Routes    []string
+		Trips     []string
+		Stops     []string
+		Shapes    []string
+		Transfers int
+		Pathways  int
+	}
+	for _, tc := range []struct {
+		name         string
+		filter       StaticFilter
+		topologyOnly bool
+		expected     summary
+	}{
+		{
+			name:   "no filter",
+			filter: StaticFilter{},
+			expected: summary{
+				Routes:    []string{"route_1", "route_2", "route_3"},
+				Trips:     []string{"trip_1", "trip_2", "trip_3"},
+				Stops:     []string{"station_1", "platform_1", "entrance_1", "stop_2", "stop_3"},
+				Shapes:    []string{"shape_1", "shape_3"},
+				Transfers: 2,
+				Pathways:  1,
+			},
+		},
+		{
+			name:   "route IDs",
+			filter: StaticFilter{RouteIDs: []string{"route_1"}},
+			expected: summary{
+				Routes:    []string{"route_1"},
+				Trips:     []string{"trip_1"},
+				Stops:     []string{"station_1", "platform_1", "entrance_1", "stop_2"},
+				Shapes:    []string{"shape_1"},
+				Transfers: 1,
+				Pathways:  1,
+			},
+		},
+		{
+			name:   "agency IDs",
+			filter: StaticFilter{AgencyIDs: []string{"agency_2"}},
+			expected: summary{
+				Routes: []string{"route_3"},
+				Trips:  []string{"trip_3"},
+				Stops:  []string{"stop_3"},
+				Shapes: []string{"shape_3"},
+			},
+		},
+		{
+			name: "date window",
+			filter: StaticFilter{
+				StartDate: time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2022, 7, 10, 0, 0, 0, 0, time.UTC),
+			},
+			expected: summary{
+				Routes: []string{"route_1", "route_2", "route_3"},
+				Trips:  []string{"trip_2"},
+				Stops:  []string{"stop_2"},
+			},
+		},
+		{
+			name: "bounding box",
+			filter: StaticFilter{
+				BoundingBox: &BoundingBox{MinLatitude: 0.5, MinLongitude: 0.5, MaxLatitude: 1.5, MaxLongitude: 1.5},
+			},
+			expected: summary{
+				Routes:    []string{"route_1", "route_2", "route_3"},
+				Trips:     []string{"trip_1"},
+				Stops:     []string{"station_1", "platform_1", "entrance_1", "stop_2"},
+				Shapes:    []string{"shape_1"},
+				Transfers: 1,
+				Pathways:  1,
+			},
+		},
+		{
+			// The stop times are not parsed, so no stops are removed.
+			name:         "route IDs topology only",
+			filter:       StaticFilter{RouteIDs: []string{"route_1"}},
+			topologyOnly: true,
+			expected: summary{
+				Routes:    []string{"route_1"},
+				Stops:     []string{"station_1", "platform_1", "entrance_1", "stop_2", "stop_3"},
+				Transfers: 2,
+				Pathways:  1,
+			},
+		},
+	} {
+		for _, visit := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s visit=%t", tc.name, visit), func(t *testing.T) {
+				opts := ParseStaticOptions{Filter: tc.filter, TopologyOnly: tc.topologyOnly}
+				if visit {
+					opts.VisitStopTimes = func(*ScheduledTrip, []ScheduledStopTime) {}
+				}
+				actual, err := ParseStatic(content, opts)
+				if err != nil {
+					t.Fatalf("error when parsing: %s", err)
+				}
+
+				stops := map[*Stop]bool{}
+				for i := range actual.Stops {
+					stops[&actual.Stops[i]] = true
+				}
+				checkStop := func(stop *Stop) {
+					if stop != nil && !stops[stop] {
+						t.Errorf("stop %s is not in the stops list", stop.Id)
+					}
+				}
+				var s summary
+				for _, route := range actual.Routes {
+					s.Routes = append(s.Routes, route.Id)
+				}
+				for _, trip := range actual.Trips {
+					s.Trips = append(s.Trips, trip.ID)
+					for _, stopTime := range trip.StopTimes {
+						checkStop(stopTime.Stop)
+					}
+				}
+				for _, stop := range actual.Stops {
+					s.Stops = append(s.Stops, stop.Id)
+					checkStop(stop.Parent)
+				}
+				for _, shape := range actual.Shapes {
+					s.Shapes = append(s.Shapes, shape.ID)
+				}
+				for _, transfer := range actual.Transfers {
+					checkStop(transfer.From)
+					checkStop(transfer.To)
+				}
+				for _, pathway := range actual.Pathways {
+					checkStop(pathway.From)
+					checkStop(pathway.To)
+				}
+				s.Transfers = len(actual.Transfers)
+				s.Pathways = len(actual.Pathways)
+				if diff := cmp.Diff(tc.expected, s); diff != "" {
+					t.Errorf("filtered feed not the same: %s", diff)
+				}
+				if len(actual.Warnings) != 0 {
+					t.Errorf("got warnings %v, want none", actual.Warnings)
+				}
+			})
+		}
+	}
+}
+
+func TestParseGtfsTimeToDuration(t *testing.T) {
+	for _, tc := range []struct {
+		input      string
+		expected   time.Duration
+		expectedOk bool
+	}{
+		{"08:05:06", 8*time.Hour + 5*time.Minute + 6*time.Second, true},
+		{" 8:05:06 ", 8*time.Hour + 5*time.Minute + 6*time.Second, true},
+		{"25:00:00", 25 * time.Hour, true},
+		{"00:00:00", 0, true},
+		{"", 0, false},
+		{"   ", 0, false},
+		{"::", 0, false},
+		{"08::00", 0, false},
+		{"08:00:00:00", 0, false},
+		{"8am", 0, false},
+	} {
+		actual, ok := parseGtfsTimeToDuration(tc.input)
+		if ok != tc.expectedOk || (ok && actual != tc.expected) {
+			t.Errorf("parseGtfsTimeToDuration(%q) = %s, %t, want %s, %t", tc.input, actual, ok, tc.expected, tc.expectedOk)
+		}
+	}
+}
+
+func TestServiceRunsBetween(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	// May 2 2022 is a Monday.
+	mondays := Service{
+		Monday:       true,
+		StartDate:    date(2022, 5, 1),
+		EndDate:      date(2022, 5, 31),
+		RemovedDates: []time.Time{date(2022, 5, 2), date(2022, 5, 9)},
+	}
+	for _, tc := range []struct {
+		desc     string
+		service  Service
+		start    time.Time
+		end      time.Time
+		expected bool
+	}{
+		{
+			desc:     "runs in window",
+			service:  mondays,
+			start:    date(2022, 5, 1),
+			end:      date(2022, 5, 31),
+			expected: true,
+		},
+		{
+			desc:     "all days in window removed",
+			service:  mondays,
+			start:    date(2022, 5, 1),
+			end:      date(2022, 5, 15),
+			expected: false,
+		},
+		{
+			desc:     "unbounded window",
+			service:  mondays,
+			expected: true,
+		},
+		{
+			desc:     "only added date in window",
+			service:  Service{StartDate: date(2022, 5, 1), EndDate: date(2022, 5, 31), AddedDates: []time.Time{date(2022, 5, 20)}},
+			start:    date(2022, 5, 15),
+			end:      date(2022, 5, 25),
+			expected: true,
+		},
+		{
+			desc:     "never runs over many years",
+			service:  Service{StartDate: date(1, 1, 1), EndDate: date(9999, 12, 31)},
+			expected: false,
+		},
+	} {
+		if actual := tc.service.runsBetween(tc.start, tc.end); actual != tc.expected {
+			t.Errorf("%s: runsBetween(%s, %s) = %t, want %t", tc.desc, tc.start, tc.end, actual, tc.expected)
+		}
+	}
+}
+
+func TestParseStatic_FilterBoundingBoxNonContiguous(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id,stop_lat,stop_lon",
+		"inside,1,1",
+		"outside,5,5",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id",
+		"route_id,service_id,trip_1",
+		"route_id,service_id,trip_2",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_2,outside,1,08:00:00,08:00:00",
+		"trip_1,inside,1,08:00:00,08:00:00",
+		"trip_1,outside,2,08:10:00,08:10:00",
+		"trip_2,inside,2,08:10:00,08:10:00",
+	).build()
+
+	for _, visit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("visit=%t", visit), func(t *testing.T) {
+			opts := ParseStaticOptions{
+				Filter: StaticFilter{
+					BoundingBox: &BoundingBox{MinLatitude: 0, MinLongitude: 0, MaxLatitude: 2, MaxLongitude: 2},
+				},
+			}
+			if visit {
+				opts.VisitStopTimes = func(*ScheduledTrip, []ScheduledStopTime) {}
+			}
+			actual, err := ParseStatic(content, opts)
+			if err != nil {
+				t.Fatalf("error when parsing: %s", err)
+			}
+			var tripIDs []string
+			for _, trip := range actual.Trips {
+				tripIDs = append(tripIDs, trip.ID)
+			}
+			if diff := cmp.Diff([]string{"trip_1"}, tripIDs); diff != "" {
+				t.Errorf("trips not the same: %s", diff)
+			}
+			var kinds []warnings.StaticWarningKind
+			for _, warning := range actual.Warnings {
+				kinds = append(kinds, warning.Kind)
+			}
+			if diff := cmp.Diff([]warnings.StaticWarningKind{warnings.NonContiguousStopTimes{TripID: "trip_2"}}, kinds); diff != "" {
+				t.Errorf("warnings not the same: %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseStatic_VisitStopTimes(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id",
+		"stop_1",
+		"stop_2",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id",
+		"route_id,service_id,trip_1",
+		"route_id,service_id,trip_2",
+		"route_id,service_id,trip_3",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_1,stop_2,2,08:10:00,08:10:00",
+		"trip_1,stop_1,1,08:00:00,08:00:00",
+		"trip_2,stop_1,1,09:00:00,09:00:00",
+		"trip_2,stop_2,2,,",
+		"trip_2,stop_1,3,09:20:00,09:20:00",
+		"trip_1,stop_1,3,08:20:00,08:20:00",
+	).build()
+
+	expected, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	// The last row of trip_1 is skipped when visiting because it is not contiguous.
+	expected.Trips[0].StopTimes = expected.Trips[0].StopTimes[:2]
+
+	var visitedTrips []string
+	visitedStopTimes := map[string][]ScheduledStopTime{}
+	actual, err := ParseStatic(content, ParseStaticOptions{
+		VisitStopTimes: func(trip *ScheduledTrip, stopTimes []ScheduledStopTime) {
+			visitedTrips = append(visitedTrips, trip.ID)
+			if trip.Route == nil {
+				t.Errorf("trip %s has no route", trip.ID)
+			}
+			for i := range stopTimes {
+				if stopTimes[i].Trip != trip {
+					t.Errorf("stop time %d of trip %s has trip %v", i, trip.ID, stopTimes[i].Trip)
+				}
+			}
+			if stopTimes != nil {
+				visitedStopTimes[trip.ID] = append([]ScheduledStopTime{}, stopTimes...)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"trip_1", "trip_2", "trip_3"}, visitedTrips); diff != "" {
+		t.Errorf("visited trips not the same: %s", diff)
+	}
+	for i, trip := range actual.Trips {
+		if trip.StopTimes != nil {
+			t.Errorf("trip %s has stop times %v, want nil", trip.ID, trip.StopTimes)
+		}
+		// The visited trips have no stop times, so the trips of the stop times are compared above.
+		if diff := cmp.Diff(expected.Trips[i].StopTimes, visitedStopTimes[trip.ID], cmpopts.IgnoreFields(ScheduledStopTime{}, "Trip")); diff != "" {
+			t.Errorf("stop times of trip %s not the same: %s", trip.ID, diff)
+		}
+	}
+}
+
+func TestParseStatic_VisitStopTimesShapeDistances(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id",
+		"stop_1",
+		"stop_2",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id",
+		"route_id,service_id,trip_1",
+		"route_id,service_id,trip_2",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time,shape_dist_traveled",
+		"trip_1,stop_1,1,09:00:00,09:00:00,",
+		"trip_1,stop_2,2,,,",
+		"trip_1,stop_1,3,09:20:00,09:20:00,",
+		"trip_2,stop_1,1,10:00:00,10:00:00,0",
+		"trip_2,stop_2,2,,,1",
+		"trip_2,stop_1,3,10:30:00,10:30:00,3",
+	).build()
+
+	expected, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	// The feed has shape distances, so all trips are interpolated by shape distance and the
+	// trip without them is not interpolated.
+	for i, want := range []time.Duration{0, 10*time.Hour + 10*time.Minute} {
+		if actual := expected.Trips[i].StopTimes[1].ArrivalTime; actual != want {
+			t.Errorf("interpolated time of trip %s: got %s, want %s", expected.Trips[i].ID, actual, want)
+		}
+	}
+
+	visitedStopTimes := map[string][]ScheduledStopTime{}
+	_, err = ParseStatic(content, ParseStaticOptions{
+		VisitStopTimes: func(trip *ScheduledTrip, stopTimes []ScheduledStopTime) {
+			visitedStopTimes[trip.ID] = append([]ScheduledStopTime{}, stopTimes...)
+		},
+	})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	for _, trip := range expected.Trips {
+		if diff := cmp.Diff(trip.StopTimes, visitedStopTimes[trip.ID], cmpopts.IgnoreFields(ScheduledStopTime{}, "Trip")); diff != "" {
+			t.Errorf("stop times of trip %s not the same: %s", trip.ID, diff)
+		}
+	}
+}
+
+func TestParseStatic_InterpolateByGeometry(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id,stop_lat,stop_lon",
+		"stop_1,0,0",
+		"stop_2,0,0.01",
+		"stop_3,0,0.04",
+	).add(
+		"shapes.txt",
+		"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+		"shape_1,0,0,1",
+		"shape_1,0,0.04,2",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id,shape_id",
+		"route_id,service_id,trip_1,shape_1",
+		"route_id,service_id,trip_2,",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+		"trip_1,stop_1,1,08:00:00,08:00:00",
+		"trip_1,stop_2,2,,",
+		"trip_1,stop_3,3,08:40:00,08:40:00",
+		"trip_2,stop_1,1,09:00:00,09:00:00",
+		"trip_2,stop_2,2,,",
+		"trip_2,stop_3,3,09:40:00,09:40:00",
+	).build()
+
+	for _, tc := range []struct {
+		byGeometry bool
+		want       []time.Duration
+	}{
+		{
+			byGeometry: false,
+			want:       []time.Duration{8*time.Hour + 20*time.Minute, 9*time.Hour + 20*time.Minute},
+		},
+		{
+			byGeometry: true,
+			want:       []time.Duration{8*time.Hour + 10*time.Minute, 9*time.Hour + 10*time.Minute},
+		},
+	} {
+		t.Run(fmt.Sprintf("byGeometry=%t", tc.byGeometry), func(t *testing.T) {
+			static, err := ParseStatic(content, ParseStaticOptions{InterpolateByGeometry: tc.byGeometry})
+			if err != nil {
+				t.Fatalf("error when parsing: %s", err)
+			}
+			for i, trip := range static.Trips {
+				got := trip.StopTimes[1].ArrivalTime.Round(time.Second)
+				if got != tc.want[i] {
+					t.Errorf("trip %s: interpolated arrival time is %s, want %s", trip.ID, got, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseStatic_Strict(t *testing.T) {
+	content := newZipBuilderWithDefaults().add(
+		"stop_times.txt",
+		"trip_id,stop_id,stop_sequence",
+		"trip_id,stop_id,1",
+		"unknown_trip,stop_id,2",
+		"trip_id,stop_id,three",
+	).add(
+		"feed_info.txt",
+		"feed_publisher_name,feed_publisher_url,feed_lang",
+		"a,b,en",
+		"c,d,en",
+	).build()
+
+	for _, tc := range []struct {
+		name          string
+		opts          ParseStaticOptions
+		expectedCodes []string
+	}{
+		{
+			name: "lenient",
+		},
+		{
+			name:          "strict",
+			opts:          ParseStaticOptions{Strict: true},
+			expectedCodes: []string{"invalid_reference", "invalid_value"},
+		},
+		{
+			name: "strict with allowed code",
+			opts: ParseStaticOptions{
+				Strict:      true,
+				StrictCodes: map[string]bool{"invalid_reference": false},
+			},
+			expectedCodes: []string{"invalid_value"},
+		},
+		{
+			name: "lenient with strict code",
+			opts: ParseStaticOptions{
+				StrictCodes: map[string]bool{"additional_row": true},
+			},
+			expectedCodes: []string{"additional_row"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			static, err := ParseStatic(content, tc.opts)
+			if tc.expectedCodes == nil {
+				if err != nil {
+					t.Fatalf("error when parsing: %s", err)
+				}
+				if len(static.Warnings) != 3 {
+					t.Errorf("got %d warnings, want 3", len(static.Warnings))
+				}
+				return
+			}
+			var strictErr *StrictError
+			if !errors.As(err, &strictErr) {
+				t.Fatalf("got error %v, want a *StrictError", err)
+			}
+			var codes []string
+			for _, warning := range strictErr.Warnings {
+				codes = append(codes, warning.Kind.Code())
+			}
+			sort.Strings(codes)
+			if diff := cmp.Diff(tc.expectedCodes, codes); diff != "" {
+				t.Errorf("warning codes not the same: %s", diff)
+			}
+		})
+	}
+}
+
+func FuzzParseStatic(f *testing.F) {
+	files := []constants.StaticFile{
+		constants.AgencyFile,
+		constants.RoutesFile,
+		constants.StopsFile,
+		constants.CalendarFile,
+		constants.CalendarDatesFile,
+		constants.TripsFile,
+		constants.StopTimesFile,
+		constants.ShapesFile,
+		constants.FrequenciesFile,
+		constants.TransfersFile,
+		constants.LocationsFile,
+		constants.FareAttributesFile,
+		constants.FareRulesFile,
+		constants.AreasFile,
+		constants.StopAreasFile,
+		constants.TimeframesFile,
+		constants.RouteNetworksFile,
+		constants.RiderCategoriesFile,
+		constants.FareMediaFile,
+		constants.FareProductsFile,
+		constants.FareLegRulesFile,
+		constants.FareLegJoinRulesFile,
+		constants.FareTransferRulesFile,
+		constants.LevelsFile,
+		constants.PathwaysFile,
+		constants.FeedInfoFile,
+		constants.TranslationsFile,
+		constants.AttributionsFile,
+		constants.LocationGroupsFile,
+		constants.LocationGroupStopsFile,
+		constants.BookingRulesFile,
+	}
+	base := newFuzzZipBuilder()
+	for i, file := range files {
+		f.Add(uint8(i), base.m[string(file)])
+	}
+	f.Add(uint8(6), "trip_id,arrival_time,departure_time,stop_id,stop_sequence,shape_dist_traveled\n"+
+		"trip_id,08:00:00,08:00:00,stop_id,1,0\n"+
+		"trip_id,,,stop_id,2,\n"+
+		"trip_id,09:00:00,09:00:00,stop_id,3,10")
+	f.Add(uint8(7), "shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence\nshape_id,lat,lon,seq")
+	f.Fuzz(func(t *testing.T, file uint8, content string) {
+		b := newFuzzZipBuilder().add(string(files[int(file)%len(files)]), content).build()
+		// Only panics are failures; errors and warnings are expected for most inputs.
+		ParseStatic(b, ParseStaticOptions{})
+	})
+}
+
+// newFuzzZipBuilder returns a feed using every file the parser reads, so that fuzzing one file exercises the
+// references between it and the others.
+func newFuzzZipBuilder() *zipBuilder {
+	return newZipBuilderWithDefaults().add(
+		"stops.txt",
+		"stop_id,stop_name,location_type,parent_station,level_id,zone_id,stop_lat,stop_lon",
+		"station,Station,1,,,,0,0",
+		"stop_id,Platform,0,station,platform,zone_1,0,0",
+		"entrance,Entrance,2,station,street,,0,0",
+		"stop_2,Other,0,,,zone_2,1,1",
+	).add(
+		"calendar_dates.txt",
+		"service_id,date,exception_type",
+		"service_id,20220505,1",
+		"service_id,20220506,2",
+	).add(
+		"stop_times.txt",
+		"trip_id,stop_sequence,stop_id,location_group_id,location_id,arrival_time,departure_time,"+
+			"start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_booking_rule_id,drop_off_booking_rule_id,"+
+			"shape_dist_traveled",
+		"trip_id,1,stop_id,,,08:00:00,08:00:00,,,,,0",
+		"trip_id,2,,,zone,,,08:00:00,10:00:00,same_day,prior_day,",
+		"trip_id,3,,group,,,,09:00:00,11:00:00,,,",
+		"trip_id,4,stop_2,,,10:00:00,10:00:00,,,,,10",
+	).add(
+		"shapes.txt",
+		"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence,shape_dist_traveled",
+		"shape_id,0,0,1,0",
+		"shape_id,1,1,2,10",
+	).add(
+		"frequencies.txt",
+		"trip_id,start_time,end_time,headway_secs,exact_times",
+		"trip_id,08:00:00,10:00:00,600,1",
+	).add(
+		"transfers.txt",
+		"from_stop_id,to_stop_id,from_route_id,to_route_id,from_trip_id,to_trip_id,transfer_type,min_transfer_time",
+		"stop_id,stop_2,,,,,2,120",
+		",,route_id,route_id,,,0,",
+		",,,,trip_id,trip_id,4,",
+	).add(
+		"locations.geojson",
+		`{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "zone", "properties": {}, `+
+			`"geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}]}`,
+	).add(
+		"fare_attributes.txt",
+		"fare_id,price,currency_type,payment_method,transfers,agency_id,transfer_duration",
+		"fare,2.50,USD,0,1,a,3600",
+	).add(
+		"fare_rules.txt",
+		"fare_id,route_id,origin_id,destination_id,contains_id",
+		"fare,route_id,zone_1,zone_2,",
+		"fare,,,,zone_1",
+	).add(
+		"areas.txt",
+		"area_id,area_name",
+		"area_1,Downtown",
+	).add(
+		"stop_areas.txt",
+		"area_id,stop_id",
+		"area_1,stop_id",
+	).add(
+		"timeframes.txt",
+		"timeframe_group_id,start_time,end_time,service_id",
+		"peak,07:00:00,09:00:00,service_id",
+	).add(
+		"route_networks.txt",
+		"network_id,route_id",
+		"network_1,route_id",
+	).add(
+		"rider_categories.txt",
+		"rider_category_id,rider_category_name,is_default_fare_category,eligibility_url",
+		"adult,Adult,1,",
+	).add(
+		"fare_media.txt",
+		"fare_media_id,fare_media_name,fare_media_type",
+		"card,Transit card,2",
+	).add(
+		"fare_products.txt",
+		"fare_product_id,fare_product_name,rider_category_id,fare_media_id,amount,currency",
+		"single,Single ride,adult,card,2.75,USD",
+		"transfer,Transfer,,,0.25,USD",
+	).add(
+		"fare_leg_rules.txt",
+		"leg_group_id,network_id,from_area_id,to_area_id,from_timeframe_group_id,to_timeframe_group_id,fare_product_id,rule_priority",
+		"leg_1,network_1,area_1,,peak,,single,1",
+		"leg_2,,,,,,single,",
+	).add(
+		"fare_leg_join_rules.txt",
+		"from_network_id,to_network_id,from_stop_id,to_stop_id",
+		"network_1,network_1,stop_id,stop_id",
+	).add(
+		"fare_transfer_rules.txt",
+		"from_leg_group_id,to_leg_group_id,transfer_count,duration_limit,duration_limit_type,fare_transfer_type,fare_product_id",
+		"leg_1,leg_2,1,5400,1,0,transfer",
+		"leg_2,leg_2,-1,,,2,",
+	).add(
+		"levels.txt",
+		"level_id,level_index,level_name",
+		"street,0,Street",
+		"platform,-1,Platform",
+	).add(
+		"pathways.txt",
+		"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,length,traversal_time,stair_count,max_slope,min_width",
+		"stairs,entrance,stop_id,2,1,10,60,-20,,1.5",
+		"elevator,stop_id,entrance,5,0,,30,,,",
+	).add(
+		"feed_info.txt",
+		"feed_publisher_name,feed_publisher_url,feed_lang,default_lang",
+		"Publisher,https://example.com,mul,fr",
+	).add(
+		"translations.txt",
+		"table_name,field_name,language,translation,record_id,record_sub_id,field_value",
+		"agency,agency_name,fr,Agence,a,,",
+		"stop_times,stop_headsign,fr,Centre,trip_id,1,",
+		"stops,stop_name,fr,Gare,,,Station",
+	).add(
+		"attributions.txt",
+		"attribution_id,agency_id,route_id,trip_id,organization_name,is_producer,is_operator,is_authority",
+		"feed,,,,Producer,1,,",
+		"route,,route_id,,Operator,0,1,0",
+	).add(
+		"location_groups.txt",
+		"location_group_id,location_group_name",
+		"group,Group",
+	).add(
+		"location_group_stops.txt",
+		"location_group_id,stop_id",
+		"group,stop_id",
+		"group,stop_2",
+	).add(
+		"booking_rules.txt",
+		"booking_rule_id,booking_type,prior_notice_duration_min,prior_notice_duration_max,prior_notice_last_day,"+
+			"prior_notice_last_time,prior_notice_service_id,message,phone_number",
+		"same_day,1,30,120,,,,Call ahead,555-0100",
+		"prior_day,2,,,1,17:00:00,service_id,,",
+	)
+}
+
+// linkStopTimes sets the trip of each stop time, which cannot be done in a composite literal.
+func linkStopTimes(trips []ScheduledTrip) {
+	for i := range trips {
+		for j := range trips[i].StopTimes {
+			trips[i].StopTimes[j].Trip = &trips[i]
+		}
+	}
+}
+
+type zipBuilder struct {
+	m map[string]string
+}
+
+func newZipBuilder() *zipBuilder {
+	return (&zipBuilder{m: map[string]string{}}).add(
+		"agency.txt", "agency_id,agency_name,agency_url,agency_timezone",
+	).add(
+		"routes.txt", "route_id,route_type",
+	).add(
+		"stops.txt", "stop_id",
+	).add(
+		"transfers.txt", "from_stop_id,to_stop_id",
+	).add(
+		"trips.txt", "route_id,service_id,trip_id",
+	).add(
+		"stop_times.txt", "stop_id,trip_id,stop_sequence",
+	)
+}
+
+func newZipBuilderWithDefaults() *zipBuilder {
+	return newZipBuilder().add(
+		"agency.txt",
+		"agency_id,agency_name,agency_url,agency_timezone\na,b,c,d",
+	).add(
+		"routes.txt",
+		"route_id,route_type\nroute_id,3",
+	).add(
+		"stops.txt",
+		"stop_id\nstop_id",
+	).add(
+		"calendar.txt",
+		"service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date\n"+
+			"service_id,0,0,0,0,0,0,0,20220504,20220507",
+	).add(
+		"stop_times.txt",
+		"stop_id,trip_id,arrival_time,departure_time,stop_sequence,stop_headsign",
+	).add(
+		"trips.txt",
+		"route_id,service_id,trip_id\nroute_id,service_id,trip_id")
+}
+
+func (z *zipBuilder) add(fileName string, fileContent ...string) *zipBuilder {
+	z.m[fileName] = strings.Join(fileContent, "\n")
+	return z
+}
+
+func (z *zipBuilder) build() []byte {
+	var b bytes.Buffer
+	zipWriter := zip.NewWriter(&b)
+	for fileName, fileContent := range z.m {
+		fileWriter, err := zipWriter.Create(fileName)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := io.Copy(fileWriter, bytes.NewBufferString(fileContent)); err != nil {
+			panic(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		panic(err)
+	}
+	return b.Bytes()
+}
+
+func ptr[T any](t T) *T {
+	return &t
+}
+
+func TestScheduledStopTime_Instants(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %s", err)
+	}
+	// Daylight saving time starts on this day, so noon minus 12 hours is 23:00 on the previous day.
+	serviceDate := time.Date(2022, 3, 13, 0, 0, 0, 0, newYork)
+	station := &Stop{Timezone: "America/Chicago"}
+	for _, tc := range []struct {
+		desc         string
+		stop         *Stop
+		wantLocation string
+		wantClock    string
+	}{
+		{
+			desc:         "stop without timezone",
+			stop:         &Stop{},
+			wantLocation: "America/New_York",
+			wantClock:    "08:00",
+		},
+		{
+			desc:         "stop with timezone",
+			stop:         &Stop{Timezone: "America/Chicago"},
+			wantLocation: "America/Chicago",
+			wantClock:    "07:00",
+		},
+		{
+			desc:         "stop inheriting timezone of parent",
+			stop:         &Stop{Parent: station},
+			wantLocation: "America/Chicago",
+			wantClock:    "07:00",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			stopTime := ScheduledStopTime{
+				Stop:          tc.stop,
+				ArrivalTime:   8 * time.Hour,
+				DepartureTime: 8*time.Hour + 30*time.Minute,
+			}
+			arrival := stopTime.ArrivalInstant(serviceDate)
+			if want := time.Date(2022, 3, 13, 12, 0, 0, 0, time.UTC); !arrival.Equal(want) {
+				t.Errorf("arrival is %s, want %s", arrival, want)
+			}
+			if got := arrival.Location().String(); got != tc.wantLocation {
+				t.Errorf("arrival location is %s, want %s", got, tc.wantLocation)
+			}
+			if got := arrival.Format("15:04"); got != tc.wantClock {
+				t.Errorf("arrival clock time is %s, want %s", got, tc.wantClock)
+			}
+			departure := stopTime.DepartureInstant(serviceDate)
+			if want := arrival.Add(30 * time.Minute); !departure.Equal(want) {
+				t.Errorf("departure is %s, want %s", departure, want)
+			}
+		})
+	}
 }
@@ -0,0 +1,311 @@
+package gtfs
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/OneBusAway/go-gtfs/csv"
+	"github.com/OneBusAway/go-gtfs/warnings"
+)
+
+// Translation corresponds to a single row in the translations.txt file.
+//
+// A translation either references a specific record using RecordId and RecordSubId,
+// or applies to every record whose field has the value FieldValue.
+type Translation struct {
+	TableName   string
+	FieldName   string
+	Language    string
+	Translation string
+	RecordId    string
+	RecordSubId string
+	FieldValue  string
+}
+
+func parseTranslations(csv *csv.File) ([]Translation, []warnings.StaticWarning) {
+	var w []warnings.StaticWarning
+	tableNameColumn := csv.RequiredColumn("table_name")
+	fieldNameColumn := csv.RequiredColumn("field_name")
+	languageColumn := csv.RequiredColumn("language")
+	translationColumn := csv.RequiredColumn("translation")
+	recordIDColumn := csv.OptionalColumn("record_id")
+	recordSubIDColumn := csv.OptionalColumn("record_sub_id")
+	fieldValueColumn := csv.OptionalColumn("field_value")
+
+	if warnings := checkForMissingColumns(csv); len(warnings) > 0 {
+		return nil, warnings
+	}
+
+	var translations []Translation
+	for csv.NextRow() {
+		translation := Translation{
+			TableName:   tableNameColumn.Read(),
+			FieldName:   fieldNameColumn.Read(),
+			Language:    languageColumn.Read(),
+			Translation: translationColumn.Read(),
+			RecordId:    recordIDColumn.Read(),
+			RecordSubId: recordSubIDColumn.Read(),
+			FieldValue:  fieldValueColumn.Read(),
+		}
+		if missingKeys := csv.MissingRowKeys(); len(missingKeys) > 0 {
+			w = append(w, warnings.NewStaticWarning(csv, warnings.MissingValues{Columns: missingKeys}))
+			continue
+		}
+		if translation.TableName != "feed_info" {
+			if translation.RecordId == "" && translation.FieldValue == "" {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.MissingOneOfValues{Columns: []string{"record_id", "field_value"}}))
+				continue
+			}
+			if translation.RecordId != "" && translation.FieldValue != "" {
+				w = append(w, warnings.NewStaticWarning(csv, warnings.ConflictingValues{Columns: []string{"record_id", "field_value"}}))
+				continue
+			}
+		}
+		translations = append(translations, translation)
+	}
+	return translations, w
+}
+
+// Translator looks up translations of the fields of records in a GTFS static feed.
+type Translator struct {
+	feedInfo                *FeedInfo
+	recordToTranslation     map[translationRecordKey]string
+	fieldValueToTranslation map[translationFieldValueKey]string
+}
+
+type translationRecordKey struct {
+	tableName   string
+	fieldName   string
+	language    string
+	recordID    string
+	recordSubID string
+}
+
+type translationFieldValueKey struct {
+	tableName  string
+	fieldName  string
+	language   string
+	fieldValue string
+}
+
+// NewTranslator builds a translator for the translations in the static feed.
+func NewTranslator(s *Static) *Translator {
+	t := &Translator{
+		feedInfo:                s.FeedInfo,
+		recordToTranslation:     map[translationRecordKey]string{},
+		fieldValueToTranslation: map[translationFieldValueKey]string{},
+	}
+	for _, translation := range s.Translations {
+		language := strings.ToLower(translation.Language)
+		if translation.FieldValue != "" {
+			t.fieldValueToTranslation[translationFieldValueKey{
+				tableName:  translation.TableName,
+				fieldName:  translation.FieldName,
+				language:   language,
+				fieldValue: translation.FieldValue,
+			}] = translation.Translation
+			continue
+		}
+		t.recordToTranslation[translationRecordKey{
+			tableName:   translation.TableName,
+			fieldName:   translation.FieldName,
+			language:    language,
+			recordID:    translation.RecordId,
+			recordSubID: translation.RecordSubId,
+		}] = translation.Translation
+	}
+	return t
+}
+
+// Translate returns the value of a field of a record in the given language.
+//
+// The record must be one of *Agency, *Route, *Stop, *ScheduledTrip, *ScheduledStopTime, *FeedInfo,
+// *Level, *Pathway or *Attribution, and the field is identified by its name in the GTFS specification;
+// e.g. "stop_name".
+// Stop times are matched by record ID only if their Trip field is set.
+//
+// If there is no translation in the requested language, the following are tried in order:
+// a translation in the base language (e.g. "fr" for "fr-CA");
+// the untranslated value, if the requested language is the language of the record
+// (the agency language for agencies and routes, or the feed language otherwise);
+// a translation in the default language of the feed.
+// If none of these exist the untranslated value is returned.
+// The empty string is returned if the record type or field is not supported.
+func (t *Translator) Translate(record any, fieldName, language string) string {
+	r, ok := newTranslationRecord(record)
+	if !ok {
+		return ""
+	}
+	value, ok := r.fields[fieldName]
+	if !ok {
+		return ""
+	}
+	originalLanguage := r.language
+	if originalLanguage == "" && t.feedInfo != nil {
+		originalLanguage = t.feedInfo.Language
+	}
+	language = strings.ToLower(language)
+	var candidates []string
+	if language != "" {
+		candidates = append(candidates, language)
+		if base, _, found := strings.Cut(language, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	for _, candidate := range candidates {
+		if translation, ok := t.lookup(r, fieldName, candidate, value); ok {
+			return translation
+		}
+	}
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, originalLanguage) {
+			return value
+		}
+	}
+	if t.feedInfo != nil && t.feedInfo.DefaultLanguage != "" {
+		if translation, ok := t.lookup(r, fieldName, strings.ToLower(t.feedInfo.DefaultLanguage), value); ok {
+			return translation
+		}
+	}
+	return value
+}
+
+func (t *Translator) lookup(r translationRecord, fieldName, language, value string) (string, bool) {
+	if r.recordID != "" || r.tableName == "feed_info" {
+		translation, ok := t.recordToTranslation[translationRecordKey{
+			tableName:   r.tableName,
+			fieldName:   fieldName,
+			language:    language,
+			recordID:    r.recordID,
+			recordSubID: r.recordSubID,
+		}]
+		if ok {
+			return translation, true
+		}
+	}
+	if value == "" {
+		return "", false
+	}
+	translation, ok := t.fieldValueToTranslation[translationFieldValueKey{
+		tableName:  r.tableName,
+		fieldName:  fieldName,
+		language:   language,
+		fieldValue: value,
+	}]
+	return translation, ok
+}
+
+type translationRecord struct {
+	tableName   string
+	recordID    string
+	recordSubID string
+	// Language of the record, if it is specified by the record or its agency.
+	language string
+	fields   map[string]string
+}
+
+func newTranslationRecord(record any) (translationRecord, bool) {
+	switch record := record.(type) {
+	case *Agency:
+		return translationRecord{
+			tableName: "agency",
+			recordID:  record.Id,
+			language:  record.Language,
+			fields: map[string]string{
+				"agency_name":     record.Name,
+				"agency_url":      record.Url,
+				"agency_phone":    record.Phone,
+				"agency_fare_url": record.FareUrl,
+				"agency_email":    record.Email,
+			},
+		}, true
+	case *Route:
+		r := translationRecord{
+			tableName: "routes",
+			recordID:  record.Id,
+			fields: map[string]string{
+				"route_short_name": record.ShortName,
+				"route_long_name":  record.LongName,
+				"route_desc":       record.Description,
+				"route_url":        record.Url,
+			},
+		}
+		if record.Agency != nil {
+			r.language = record.Agency.Language
+		}
+		return r, true
+	case *Stop:
+		return translationRecord{
+			tableName: "stops",
+			recordID:  record.Id,
+			fields: map[string]string{
+				"stop_code":     record.Code,
+				"stop_name":     record.Name,
+				"stop_desc":     record.Description,
+				"stop_url":      record.Url,
+				"platform_code": record.PlatformCode,
+			},
+		}, true
+	case *ScheduledTrip:
+		return translationRecord{
+			tableName: "trips",
+			recordID:  record.ID,
+			fields: map[string]string{
+				"trip_headsign":   record.Headsign,
+				"trip_short_name": record.ShortName,
+			},
+		}, true
+	case *ScheduledStopTime:
+		r := translationRecord{
+			tableName: "stop_times",
+			fields: map[string]string{
+				"stop_headsign": record.Headsign,
+			},
+		}
+		if record.Trip != nil {
+			r.recordID = record.Trip.ID
+			r.recordSubID = strconv.Itoa(record.StopSequence)
+		}
+		return r, true
+	case *FeedInfo:
+		return translationRecord{
+			tableName: "feed_info",
+			fields: map[string]string{
+				"feed_publisher_name": record.PublisherName,
+				"feed_publisher_url":  record.PublisherUrl,
+				"feed_version":        record.Version,
+				"feed_contact_email":  record.ContactEmail,
+				"feed_contact_url":    record.ContactUrl,
+			},
+		}, true
+	case *Level:
+		return translationRecord{
+			tableName: "levels",
+			recordID:  record.Id,
+			fields: map[string]string{
+				"level_name": record.Name,
+			},
+		}, true
+	case *Pathway:
+		return translationRecord{
+			tableName: "pathways",
+			recordID:  record.Id,
+			fields: map[string]string{
+				"signposted_as":          record.SignpostedAs,
+				"reversed_signposted_as": record.ReversedSignpostedAs,
+			},
+		}, true
+	case *Attribution:
+		return translationRecord{
+			tableName: "attributions",
+			recordID:  record.Id,
+			fields: map[string]string{
+				"organization_name": record.OrganizationName,
+				"attribution_url":   record.Url,
+				"attribution_email": record.Email,
+				"attribution_phone": record.Phone,
+			},
+		}, true
+	}
+	return translationRecord{}, false
+}
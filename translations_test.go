@@ -0,0 +1,148 @@
+package gtfs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTranslate(t *testing.T) {
+	content := newZipBuilder().add(
+		"agency.txt",
+		"agency_id,agency_name,agency_url,agency_timezone,agency_lang",
+		"agency,Transit Agency,https://example.com,America/Montreal,en",
+	).add(
+		"feed_info.txt",
+		"feed_publisher_name,feed_publisher_url,feed_lang,default_lang",
+		"Publisher,https://example.com,mul,fr",
+	).add(
+		"routes.txt",
+		"route_id,route_type,route_long_name",
+		"route,3,Main Street",
+	).add(
+		"stops.txt",
+		"stop_id,stop_name",
+		"stop_1,Central Station",
+		"stop_2,Central Station",
+		"stop_3,Airport",
+	).add(
+		"translations.txt",
+		"table_name,field_name,language,translation,record_id,record_sub_id,field_value",
+		"agency,agency_name,fr,Agence de transport,agency,,",
+		"routes,route_long_name,fr,Rue Principale,route,,",
+		"stops,stop_name,fr,Gare Centrale,,,Central Station",
+		"stops,stop_name,fr-CA,Gare Centrale du Canada,stop_2,,",
+		"stops,stop_name,de,Flughafen,stop_3,,",
+		"feed_info,feed_publisher_name,fr,Éditeur,,,",
+		"stops,stop_name,fr,Missing record,,,",
+		"stops,stop_name,fr,Both,stop_1,,Central Station",
+	).build()
+
+	static, err := ParseStatic(content, ParseStaticOptions{})
+	if err != nil {
+		t.Fatalf("error when parsing: %s", err)
+	}
+	if diff := cmp.Diff(static.Translations, []Translation{
+		{TableName: "agency", FieldName: "agency_name", Language: "fr", Translation: "Agence de transport", RecordId: "agency"},
+		{TableName: "routes", FieldName: "route_long_name", Language: "fr", Translation: "Rue Principale", RecordId: "route"},
+		{TableName: "stops", FieldName: "stop_name", Language: "fr", Translation: "Gare Centrale", FieldValue: "Central Station"},
+		{TableName: "stops", FieldName: "stop_name", Language: "fr-CA", Translation: "Gare Centrale du Canada", RecordId: "stop_2"},
+		{TableName: "stops", FieldName: "stop_name", Language: "de", Translation: "Flughafen", RecordId: "stop_3"},
+		{TableName: "feed_info", FieldName: "feed_publisher_name", Language: "fr", Translation: "Éditeur"},
+	}); diff != "" {
+		t.Errorf("translations not the same: %s", diff)
+	}
+
+	translator := NewTranslator(static)
+	for _, tc := range []struct {
+		desc      string
+		record    any
+		fieldName string
+		language  string
+		expected  string
+	}{
+		{
+			desc:      "record id",
+			record:    &static.Agencies[0],
+			fieldName: "agency_name",
+			language:  "fr",
+			expected:  "Agence de transport",
+		},
+		{
+			desc:      "agency language returns the original value",
+			record:    &static.Routes[0],
+			fieldName: "route_long_name",
+			language:  "en",
+			expected:  "Main Street",
+		},
+		{
+			desc:      "field value",
+			record:    &static.Stops[0],
+			fieldName: "stop_name",
+			language:  "fr",
+			expected:  "Gare Centrale",
+		},
+		{
+			desc:      "record id for regional language",
+			record:    &static.Stops[1],
+			fieldName: "stop_name",
+			language:  "fr-CA",
+			expected:  "Gare Centrale du Canada",
+		},
+		{
+			desc:      "fall back to base language",
+			record:    &static.Stops[0],
+			fieldName: "stop_name",
+			language:  "FR-ca",
+			expected:  "Gare Centrale",
+		},
+		{
+			desc:      "fall back to default language",
+			record:    &static.Stops[0],
+			fieldName: "stop_name",
+			language:  "es",
+			expected:  "Gare Centrale",
+		},
+		{
+			desc:      "no language uses default language",
+			record:    &static.Routes[0],
+			fieldName: "route_long_name",
+			expected:  "Rue Principale",
+		},
+		{
+			desc:      "fall back to original value",
+			record:    &static.Stops[2],
+			fieldName: "stop_name",
+			language:  "es",
+			expected:  "Airport",
+		},
+		{
+			desc:      "feed info",
+			record:    static.FeedInfo,
+			fieldName: "feed_publisher_name",
+			language:  "fr",
+			expected:  "Éditeur",
+		},
+		{
+			desc:      "unknown field",
+			record:    &static.Stops[0],
+			fieldName: "stop_lat",
+			language:  "fr",
+			expected:  "",
+		},
+		{
+			desc:      "unsupported record",
+			record:    &static.Services,
+			fieldName: "service_id",
+			language:  "fr",
+			expected:  "",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual := translator.Translate(tc.record, tc.fieldName, tc.language)
+			if actual != tc.expected {
+				t.Errorf("Translate() = %q, want %q", actual, tc.expected)
+			}
+		})
+	}
+}
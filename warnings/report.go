@@ -0,0 +1,84 @@
+package warnings
+
+import (
+	"sort"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+)
+
+// StaticReport summarizes the warnings raised while parsing a GTFS static feed.
+//
+// Warnings with the same code in the same file are grouped together.
+// The report can be serialized to JSON using the encoding/json package.
+type StaticReport struct {
+	// Total number of warnings.
+	Count int `json:"count"`
+	// Groups of warnings, ordered by decreasing severity and then by code and file.
+	Groups []StaticReportGroup `json:"groups"`
+}
+
+// StaticReportGroup contains the warnings with the same code in the same file.
+type StaticReportGroup struct {
+	Code     string               `json:"code"`
+	Severity Severity             `json:"severity"`
+	File     constants.StaticFile `json:"file"`
+	// Number of warnings in the group.
+	Count int `json:"count"`
+	// Content of the header of the file.
+	HeaderContent []string `json:"headerContent,omitempty"`
+	// The first few warnings in the group.
+	Samples []StaticReportSample `json:"samples"`
+}
+
+// StaticReportSample is a single warning in a StaticReportGroup.
+type StaticReportSample struct {
+	Message    string   `json:"message"`
+	RowNumber  int      `json:"rowNumber"`
+	RowContent []string `json:"rowContent,omitempty"`
+}
+
+// NewStaticReport builds a report from the warnings.
+//
+// At most maxSamples warnings are included as samples in each group.
+func NewStaticReport(w []StaticWarning, maxSamples int) *StaticReport {
+	type key struct {
+		code string
+		file constants.StaticFile
+	}
+	report := &StaticReport{Count: len(w)}
+	keyToIndex := map[key]int{}
+	for _, warning := range w {
+		k := key{code: warning.Kind.Code(), file: warning.File}
+		i, ok := keyToIndex[k]
+		if !ok {
+			i = len(report.Groups)
+			keyToIndex[k] = i
+			report.Groups = append(report.Groups, StaticReportGroup{
+				Code:          k.code,
+				Severity:      warning.Kind.Severity(),
+				File:          k.file,
+				HeaderContent: warning.HeaderContent,
+			})
+		}
+		group := &report.Groups[i]
+		group.Count++
+		if len(group.Samples) < maxSamples {
+			group.Samples = append(group.Samples, StaticReportSample{
+				Message:    warning.Kind.Error(),
+				RowNumber:  warning.RowNumber,
+				RowContent: warning.RowContent,
+			})
+		}
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		a, b := &report.Groups[i], &report.Groups[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.File < b.File
+	})
+	return report
+}
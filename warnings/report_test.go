@@ -0,0 +1,86 @@
+package warnings
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/OneBusAway/go-gtfs/constants"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewStaticReport(t *testing.T) {
+	header := []string{"trip_id", "stop_id"}
+	w := []StaticWarning{
+		{
+			Kind:          InvalidReference{Column: "stop_id", Value: "a"},
+			File:          constants.StopTimesFile,
+			RowNumber:     1,
+			RowContent:    []string{"trip", "a"},
+			HeaderContent: header,
+		},
+		{
+			Kind:          MissingColumns{Columns: []string{"shape_pt_lat"}},
+			File:          constants.ShapesFile,
+			HeaderContent: []string{"shape_id"},
+		},
+		{
+			Kind:          InvalidReference{Column: "stop_id", Value: "b"},
+			File:          constants.StopTimesFile,
+			RowNumber:     2,
+			RowContent:    []string{"trip", "b"},
+			HeaderContent: header,
+		},
+		{
+			Kind:          InvalidReference{Column: "stop_id", Value: "c"},
+			File:          constants.StopTimesFile,
+			RowNumber:     3,
+			RowContent:    []string{"trip", "c"},
+			HeaderContent: header,
+		},
+	}
+
+	report := NewStaticReport(w, 2)
+
+	expected := &StaticReport{
+		Count: 4,
+		Groups: []StaticReportGroup{
+			{
+				Code:          "missing_columns",
+				Severity:      Severity_Fatal,
+				File:          constants.ShapesFile,
+				Count:         1,
+				HeaderContent: []string{"shape_id"},
+				Samples: []StaticReportSample{
+					{Message: "csv file is missing columns [shape_pt_lat]"},
+				},
+			},
+			{
+				Code:          "invalid_reference",
+				Severity:      Severity_Error,
+				File:          constants.StopTimesFile,
+				Count:         3,
+				HeaderContent: header,
+				Samples: []StaticReportSample{
+					{Message: `stop_id "a" does not reference a valid record`, RowNumber: 1, RowContent: []string{"trip", "a"}},
+					{Message: `stop_id "b" does not reference a valid record`, RowNumber: 2, RowContent: []string{"trip", "b"}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Errorf("report not the same: %s", diff)
+	}
+
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %s", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal report: %s", err)
+	}
+	group := decoded["groups"].([]any)[1].(map[string]any)
+	if group["severity"] != "ERROR" || group["code"] != "invalid_reference" || group["count"] != 3.0 {
+		t.Errorf("unexpected JSON group %v", group)
+	}
+}
@@ -42,7 +42,46 @@ type StaticWarningKind interface {
 	// Text of the warning message.
 	Error() string // TODO: Message()
 
-	// TODO: Fatal() ? And convert all parsing errors into warnings
+	// Code is a stable identifier for the kind of warning, like "missing_columns".
+	// Codes never change once released, so they can be used to aggregate warnings across feeds and versions.
+	Code() string
+
+	// Severity of the warning.
+	Severity() Severity
+}
+
+// Severity describes how serious a warning is.
+type Severity int32
+
+const (
+	// The feed is valid but could be improved.
+	Severity_Info Severity = 0
+	// Something in the feed is probably wrong but no data was lost.
+	Severity_Warning Severity = 1
+	// Data in the feed was skipped or ignored.
+	Severity_Error Severity = 2
+	// A whole file in the feed could not be parsed.
+	Severity_Fatal Severity = 3
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Severity_Info:
+		return "INFO"
+	case Severity_Warning:
+		return "WARNING"
+	case Severity_Error:
+		return "ERROR"
+	case Severity_Fatal:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalText marshals the severity as its string representation, for example in JSON reports.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
 }
 
 type MissingColumns struct {
@@ -53,6 +92,14 @@ func (w MissingColumns) Error() string {
 	return fmt.Sprintf("csv file is missing columns %s", w.Columns)
 }
 
+func (w MissingColumns) Code() string {
+	return "missing_columns"
+}
+
+func (w MissingColumns) Severity() Severity {
+	return Severity_Fatal
+}
+
 type AgencyMissingValues struct {
 	AgencyID string
 	Columns  []string
@@ -61,3 +108,286 @@ type AgencyMissingValues struct {
 func (w AgencyMissingValues) Error() string {
 	return fmt.Sprintf("agency %q is missing values %s", w.AgencyID, w.Columns)
 }
+
+func (w AgencyMissingValues) Code() string {
+	return "agency_missing_values"
+}
+
+func (w AgencyMissingValues) Severity() Severity {
+	return Severity_Error
+}
+
+// MissingOneOfColumns is raised when a csv file has none of a set of columns, at least one of which is required.
+// The file is skipped.
+type MissingOneOfColumns struct {
+	Columns []string
+}
+
+func (w MissingOneOfColumns) Error() string {
+	return fmt.Sprintf("csv file must have at least one of columns %s", w.Columns)
+}
+
+func (w MissingOneOfColumns) Code() string {
+	return "missing_one_of_columns"
+}
+
+func (w MissingOneOfColumns) Severity() Severity {
+	return Severity_Fatal
+}
+
+// MissingValues is raised when a row is missing values for required columns.
+// The row is skipped.
+type MissingValues struct {
+	Columns []string
+}
+
+func (w MissingValues) Error() string {
+	return fmt.Sprintf("row is missing values %s", w.Columns)
+}
+
+func (w MissingValues) Code() string {
+	return "missing_values"
+}
+
+func (w MissingValues) Severity() Severity {
+	return Severity_Error
+}
+
+// MissingOneOfValues is raised when a row has no value for any of a set of columns,
+// at least one of which is required.
+// The row is skipped.
+type MissingOneOfValues struct {
+	Columns []string
+}
+
+func (w MissingOneOfValues) Error() string {
+	return fmt.Sprintf("row must have a value for one of %s", w.Columns)
+}
+
+func (w MissingOneOfValues) Code() string {
+	return "missing_one_of_values"
+}
+
+func (w MissingOneOfValues) Severity() Severity {
+	return Severity_Error
+}
+
+// ConflictingValues is raised when a row has values for more than one of a set of mutually exclusive columns.
+// The row is skipped.
+type ConflictingValues struct {
+	Columns []string
+}
+
+func (w ConflictingValues) Error() string {
+	return fmt.Sprintf("row must have a value for at most one of %s", w.Columns)
+}
+
+func (w ConflictingValues) Code() string {
+	return "conflicting_values"
+}
+
+func (w ConflictingValues) Severity() Severity {
+	return Severity_Error
+}
+
+// InvalidValue is raised when a value cannot be parsed.
+// Depending on the column, the row is skipped or the value is ignored.
+type InvalidValue struct {
+	Column string
+	Value  string
+}
+
+func (w InvalidValue) Error() string {
+	return fmt.Sprintf("invalid %s %q", w.Column, w.Value)
+}
+
+func (w InvalidValue) Code() string {
+	return "invalid_value"
+}
+
+func (w InvalidValue) Severity() Severity {
+	return Severity_Error
+}
+
+// InvalidReference is raised when a value references a record that does not exist in the feed.
+// Depending on the column, the row is skipped or the reference is ignored.
+type InvalidReference struct {
+	Column string
+	Value  string
+}
+
+func (w InvalidReference) Error() string {
+	return fmt.Sprintf("%s %q does not reference a valid record", w.Column, w.Value)
+}
+
+func (w InvalidReference) Code() string {
+	return "invalid_reference"
+}
+
+func (w InvalidReference) Severity() Severity {
+	return Severity_Error
+}
+
+// RouteWithoutAgency is raised when a route has no agency ID and the feed has more than one agency.
+// The route is skipped.
+type RouteWithoutAgency struct {
+	RouteID string
+}
+
+func (w RouteWithoutAgency) Error() string {
+	return fmt.Sprintf("route %q has no agency_id but the feed has more than one agency", w.RouteID)
+}
+
+func (w RouteWithoutAgency) Code() string {
+	return "route_without_agency"
+}
+
+func (w RouteWithoutAgency) Severity() Severity {
+	return Severity_Error
+}
+
+// AttributionWithoutRole is raised when none of the roles of an attribution is set.
+// The attribution is skipped.
+type AttributionWithoutRole struct {
+	AttributionID string
+}
+
+func (w AttributionWithoutRole) Error() string {
+	return fmt.Sprintf("attribution %q has none of is_producer, is_operator and is_authority set", w.AttributionID)
+}
+
+func (w AttributionWithoutRole) Code() string {
+	return "attribution_without_role"
+}
+
+func (w AttributionWithoutRole) Severity() Severity {
+	return Severity_Error
+}
+
+// FareZoneWithoutStops is raised when a zone referenced in fare_rules.txt has no stops.
+type FareZoneWithoutStops struct {
+	ZoneID string
+}
+
+func (w FareZoneWithoutStops) Error() string {
+	return fmt.Sprintf("fare zone %q has no stops", w.ZoneID)
+}
+
+func (w FareZoneWithoutStops) Code() string {
+	return "fare_zone_without_stops"
+}
+
+func (w FareZoneWithoutStops) Severity() Severity {
+	return Severity_Warning
+}
+
+// AdditionalRow is raised when a file that must have a single row has more than one.
+// The additional rows are ignored.
+type AdditionalRow struct{}
+
+func (w AdditionalRow) Error() string {
+	return "file must have a single row"
+}
+
+func (w AdditionalRow) Code() string {
+	return "additional_row"
+}
+
+func (w AdditionalRow) Severity() Severity {
+	return Severity_Warning
+}
+
+// NonContiguousStopTimes is raised when stop times are streamed or filtered by a bounding box and the rows of
+// a trip are not contiguous.
+// The rows after the first contiguous block are skipped.
+type NonContiguousStopTimes struct {
+	TripID string
+}
+
+func (w NonContiguousStopTimes) Error() string {
+	return fmt.Sprintf("stop times of trip %q are not contiguous", w.TripID)
+}
+
+func (w NonContiguousStopTimes) Code() string {
+	return "non_contiguous_stop_times"
+}
+
+func (w NonContiguousStopTimes) Severity() Severity {
+	return Severity_Error
+}
+
+// InvalidLocation is raised when a location in locations.geojson cannot be parsed.
+// The location is skipped.
+type InvalidLocation struct {
+	LocationID string
+	Reason     string
+}
+
+func (w InvalidLocation) Error() string {
+	return fmt.Sprintf("location %q is invalid: %s", w.LocationID, w.Reason)
+}
+
+func (w InvalidLocation) Code() string {
+	return "invalid_location"
+}
+
+func (w InvalidLocation) Severity() Severity {
+	return Severity_Error
+}
+
+// AgencyTimezoneMismatch is raised when an agency has a different timezone than the first agency in the feed.
+// All agencies in a feed must have the same timezone; dates are interpreted in the timezone of the first agency.
+type AgencyTimezoneMismatch struct {
+	AgencyID         string
+	Timezone         string
+	ExpectedTimezone string
+}
+
+func (w AgencyTimezoneMismatch) Error() string {
+	return fmt.Sprintf("agency %q has timezone %q, but the first agency has timezone %q", w.AgencyID, w.Timezone, w.ExpectedTimezone)
+}
+
+func (w AgencyTimezoneMismatch) Code() string {
+	return "agency_timezone_mismatch"
+}
+
+func (w AgencyTimezoneMismatch) Severity() Severity {
+	return Severity_Error
+}
+
+// SameStopTransfer is raised when a transfer in transfers.txt is from a stop to the same stop and has no
+// route or trip qualifiers.
+// The transfer is skipped.
+type SameStopTransfer struct {
+	StopID string
+}
+
+func (w SameStopTransfer) Error() string {
+	return fmt.Sprintf("transfer from stop %q to the same stop", w.StopID)
+}
+
+func (w SameStopTransfer) Code() string {
+	return "same_stop_transfer"
+}
+
+func (w SameStopTransfer) Severity() Severity {
+	return Severity_Warning
+}
+
+// InvalidFile is raised when a file that is not a CSV file, like locations.geojson, cannot be decoded.
+// The whole file is skipped.
+type InvalidFile struct {
+	Reason string
+}
+
+func (w InvalidFile) Error() string {
+	return fmt.Sprintf("file cannot be decoded: %s", w.Reason)
+}
+
+func (w InvalidFile) Code() string {
+	return "invalid_file"
+}
+
+func (w InvalidFile) Severity() Severity {
+	return Severity_Fatal
+}
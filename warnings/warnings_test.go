@@ -1,7 +1,37 @@
 package warnings
 
+import "testing"
+
 // Verify that StaticWarningKind satisfies the error interface.
 var (
 	w StaticWarningKind = nil
 	e error             = w
 )
+
+func TestCodesAreUnique(t *testing.T) {
+	codes := map[string]bool{}
+	for _, kind := range []StaticWarningKind{
+		MissingColumns{},
+		AgencyMissingValues{},
+		MissingOneOfColumns{},
+		MissingValues{},
+		MissingOneOfValues{},
+		ConflictingValues{},
+		InvalidValue{},
+		InvalidReference{},
+		RouteWithoutAgency{},
+		AttributionWithoutRole{},
+		FareZoneWithoutStops{},
+		AdditionalRow{},
+		NonContiguousStopTimes{},
+		InvalidLocation{},
+		AgencyTimezoneMismatch{},
+		SameStopTransfer{},
+		InvalidFile{},
+	} {
+		if codes[kind.Code()] {
+			t.Errorf("code %q is used by more than one kind", kind.Code())
+		}
+		codes[kind.Code()] = true
+	}
+}